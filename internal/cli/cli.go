@@ -0,0 +1,163 @@
+// Package cli implements bloom's non-interactive subcommands
+package cli
+
+import (
+	"bloom/internal/feed"
+	"bloom/internal/storage"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const usage = `Usage: bloom [--config FILE] [--state FILE] [command]
+
+Without a command, bloom starts the terminal UI.
+
+Options:
+  --config FILE   Config file, or $BLOOM_CONFIG
+                  (default $XDG_CONFIG_HOME/bloom/config.json)
+  --state FILE    Read state file, or $BLOOM_STATE
+                  (default $XDG_STATE_HOME/bloom/state.json)
+
+Fetched feeds are cached in $XDG_CACHE_HOME/bloom.
+
+Commands:
+  add <url> [--category C] [--tags a,b]    Subscribe to a feed, or the feed of a site
+  rm <url|number>                          Unsubscribe from a feed
+  list [--json]                            List the feeds
+  refresh [--feed URL]                     Fetch the feeds
+  items [--unread] [--feed URL] [--json]   List the items fetched last
+  mark-read <link|id>... | --feed URL | --all
+                                           Mark items as read
+  compact                                  Drop read marks past the retention period
+  import <file>                            Add the feeds from an OPML file
+  export [file]                            Write the feeds as OPML to file, or stdout
+  serve [--addr HOST:PORT | --socket PATH] Serve the HTTP/JSON API, refreshing in the background
+  help                                     Show this help
+`
+
+// command runs a subcommand with its arguments
+type command func(args []string, stdout io.Writer) error
+
+var commands = map[string]command{
+	"add":       runAdd,
+	"rm":        runRemove,
+	"list":      runList,
+	"refresh":   runRefresh,
+	"items":     runItems,
+	"mark-read": runMarkRead,
+	"compact":   runCompact,
+	"import":    runImport,
+	"export":    runExport,
+	"serve":     runServe,
+}
+
+// ParseGlobalFlags applies the options given before the command and
+// returns the remaining arguments
+func ParseGlobalFlags(args []string) ([]string, error) {
+	fs := flag.NewFlagSet("bloom", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configPath := fs.String("config", "", "config file")
+	statePath := fs.String("state", "", "state file")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return []string{"help"}, nil
+		}
+		return nil, err
+	}
+
+	if *configPath != "" {
+		storage.SetConfigPath(*configPath)
+	}
+	if *statePath != "" {
+		storage.SetStatePath(*statePath)
+	}
+	return fs.Args(), nil
+}
+
+// Run executes the subcommand in args and returns the process exit code
+func Run(args []string) int {
+	return run(args, os.Stdout, os.Stderr)
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "bloom: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if err := cmd(args[1:], stdout); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		fmt.Fprintf(stderr, "bloom %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// parseFlags parses flags wherever they appear among args, so they can
+// follow positional arguments ("bloom add URL --category News"). It
+// returns the positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// loadConfig loads the config, warning about problems that leave it usable
+func loadConfig() (*storage.Config, error) {
+	config, err := storage.LoadConfig()
+	if err != nil && config != nil {
+		fmt.Fprintf(os.Stderr, "bloom: warning: %v\n", err)
+		return config, nil
+	}
+	return config, err
+}
+
+// newReader creates a reader with the offline cache and the configured
+// HTTP settings, as the TUI uses
+func newReader(config *storage.Config) (*feed.Reader, *storage.FeedCache, error) {
+	cache := storage.NewFeedCache()
+	reader := feed.NewReader()
+	reader.SetCache(cache)
+	if err := config.ConfigureClients(feed.NewClientFactory(), reader, feed.NewArticleFetcher()); err != nil {
+		return nil, nil, fmt.Errorf("invalid HTTP settings: %v", err)
+	}
+	return reader, cache, nil
+}
+
+// splitList parses a comma-separated flag value
+func splitList(s string) []string {
+	list := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"bloom/internal/storage"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Test Feed</title>
+<item><title>First</title><link>https://example.com/1</link></item>
+<item><title>Second</title><link>https://example.com/2</link></item>
+</channel></rss>`
+
+// useProfile keeps bloom's files in a temporary directory
+func useProfile(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("BLOOM_CONFIG", filepath.Join(dir, "config.json"))
+	t.Setenv("BLOOM_STATE", filepath.Join(dir, "state.json"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	return dir
+}
+
+// runOK runs a command and fails the test unless it succeeds
+func runOK(t *testing.T, args ...string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("bloom %s exited %d: %s", strings.Join(args, " "), code, stderr.String())
+	}
+	return stdout.String()
+}
+
+func TestHeadlessCommands(t *testing.T) {
+	useProfile(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(testFeed))
+	}))
+	defer server.Close()
+	feedURL := server.URL + "/feed.xml"
+
+	runOK(t, "add", feedURL, "--category", "Test", "--tags", "a, b")
+	// Drop the default feed so nothing leaves the test server
+	runOK(t, "rm", "1")
+
+	list := runOK(t, "list")
+	if !strings.Contains(list, feedURL) || !strings.Contains(list, "[Test]") || strings.Contains(list, "mitchellh") {
+		t.Fatalf("unexpected list:\n%s", list)
+	}
+
+	if out := runOK(t, "refresh"); !strings.Contains(out, "ok    "+feedURL+" (2 items)") {
+		t.Fatalf("unexpected refresh output:\n%s", out)
+	}
+
+	runOK(t, "mark-read", "https://example.com/1")
+
+	var items []itemJSON
+	if err := json.Unmarshal([]byte(runOK(t, "items", "--unread", "--json")), &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Title != "Second" || items[0].Feed != "Test Feed" {
+		t.Fatalf("unexpected unread items: %+v", items)
+	}
+
+	runOK(t, "mark-read", "--all")
+	if out := runOK(t, "items", "--unread"); out != "" {
+		t.Fatalf("expected no unread items, got:\n%s", out)
+	}
+}
+
+func TestItemsMergesFeedsAndSkipsFailures(t *testing.T) {
+	useProfile(t)
+	feeds := map[string]string{
+		"/a.xml": `<rss version="2.0"><channel><title>A</title>
+<item><title>A old</title><link>https://a.example.com/1</link><pubDate>Mon, 01 Jan 2024 00:00:00 +0000</pubDate></item>
+<item><title>A new</title><link>https://a.example.com/2</link><pubDate>Wed, 03 Jan 2024 00:00:00 +0000</pubDate></item>
+</channel></rss>`,
+		"/b.xml": `<rss version="2.0"><channel><title>B</title>
+<item><title>B middle</title><link>https://b.example.com/1</link><pubDate>Tue, 02 Jan 2024 00:00:00 +0000</pubDate></item>
+</channel></rss>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := feeds[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	runOK(t, "rm", "1")
+	for _, path := range []string{"/a.xml", "/missing.xml", "/b.xml"} {
+		runOK(t, "add", "--no-discover", server.URL+path)
+	}
+
+	var items []itemJSON
+	if err := json.Unmarshal([]byte(runOK(t, "items", "--json")), &items); err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	if want := "A new, B middle, A old"; strings.Join(titles, ", ") != want {
+		t.Errorf("items = %v, want %s", titles, want)
+	}
+}
+
+func TestAddRejectsDuplicate(t *testing.T) {
+	useProfile(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"add", "--no-discover", "https://mitchellh.com/feed.xml"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit 1 for a duplicate feed, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "already subscribed") {
+		t.Fatalf("unexpected error: %s", stderr.String())
+	}
+}
+
+func TestGlobalFlags(t *testing.T) {
+	dir := useProfile(t)
+	t.Cleanup(func() {
+		storage.SetConfigPath("")
+		storage.SetStatePath("")
+	})
+
+	profile := filepath.Join(dir, "work.json")
+	args, err := ParseGlobalFlags([]string{"--config", profile, "add", "--no-discover", "https://example.com/feed.xml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runOK(t, args...)
+
+	data, err := os.ReadFile(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "https://example.com/feed.xml") {
+		t.Errorf("expected the feed in %s:\n%s", profile, data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "config.json")); err == nil {
+		t.Error("expected the default profile to be left alone")
+	}
+}
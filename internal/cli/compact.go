@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"bloom/internal/storage"
+	"fmt"
+	"io"
+	"time"
+)
+
+// runCompact drops the read marks past the configured retention
+func runCompact(args []string, stdout io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments")
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	state, err := storage.LoadState()
+	if err != nil {
+		return err
+	}
+
+	total := len(state.ReadArticles)
+	dropped := storage.CompactState(config, state, storage.NewFeedCache(), time.Now())
+	if err := storage.SaveState(state); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "dropped %d of %d read marks\n", dropped, total)
+	return nil
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"bloom/internal/feed"
+	"bloom/internal/storage"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// runAdd subscribes to a feed. A site URL is resolved to its feed; when
+// the site has several, they're listed so one can be picked.
+func runAdd(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	category := fs.String("category", "", "category of the feed")
+	tags := fs.String("tags", "", "comma-separated tags")
+	noDiscover := fs.Bool("no-discover", false, "add the URL as given, without looking for feeds")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("expected one URL")
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	feedURL := storage.NormalizeFeedURL(positional[0])
+	if !*noDiscover {
+		reader, _, err := newReader(config)
+		if err != nil {
+			return err
+		}
+		client, err := config.DiscoveryClient(feed.NewClientFactory(), feedURL)
+		if err != nil {
+			return fmt.Errorf("invalid HTTP settings: %v", err)
+		}
+		found, err := reader.DiscoverWith(client, feedURL)
+		if err != nil {
+			return err
+		}
+		if len(found) > 1 {
+			var lines []string
+			for _, candidate := range found {
+				lines = append(lines, fmt.Sprintf("  %s  %s", candidate.URL, candidate.Title))
+			}
+			return fmt.Errorf("%s offers several feeds, add one of them:\n%s", feedURL, strings.Join(lines, "\n"))
+		}
+		feedURL = found[0].URL
+	}
+
+	added := config.MergeFeeds([]storage.FeedConfig{{
+		URL:      feedURL,
+		Category: *category,
+		Tags:     splitList(*tags),
+	}})
+	if len(added) == 0 {
+		return fmt.Errorf("already subscribed to %s", feedURL)
+	}
+	if err := storage.SaveConfig(config); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "added %s\n", feedURL)
+	return nil
+}
+
+// runRemove unsubscribes from a feed given by URL or by its number in list
+func runRemove(args []string, stdout io.Writer) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected one feed URL or number")
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	feedURL := args[0]
+	if n, err := strconv.Atoi(args[0]); err == nil {
+		if n < 1 || n > len(config.Feeds) {
+			return fmt.Errorf("no feed number %d", n)
+		}
+		feedURL = config.Feeds[n-1].URL
+	}
+
+	if !config.RemoveFeed(feedURL) {
+		return fmt.Errorf("not subscribed to %s", feedURL)
+	}
+	if err := storage.SaveConfig(config); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "removed %s\n", storage.NormalizeFeedURL(feedURL))
+	return nil
+}
+
+// feedJSON is a feed in the output of list --json
+type feedJSON struct {
+	URL       string   `json:"url"`
+	Title     string   `json:"title,omitempty"`
+	Category  string   `json:"category"`
+	Tags      []string `json:"tags"`
+	Paused    bool     `json:"paused"`
+	Disabled  bool     `json:"disabled"`
+	LastError string   `json:"last_error,omitempty"`
+}
+
+// runList prints the configured feeds, numbered for rm
+func runList(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	state, err := storage.LoadState()
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		feeds := []feedJSON{}
+		for _, feedConfig := range config.Feeds {
+			entry := feedJSON{
+				URL:      feedConfig.URL,
+				Title:    feedConfig.Title,
+				Category: feedConfig.Category,
+				Tags:     feedConfig.Tags,
+				Paused:   feedConfig.Paused,
+				Disabled: feedConfig.Disabled,
+			}
+			if health := state.Health(feedConfig.URL); health != nil {
+				entry.LastError = health.LastError
+			}
+			feeds = append(feeds, entry)
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(feeds)
+	}
+
+	for i, feedConfig := range config.Feeds {
+		line := fmt.Sprintf("%3d  %s", i+1, feedConfig.URL)
+		if feedConfig.Title != "" {
+			line += "  " + strconv.Quote(feedConfig.Title)
+		}
+		if feedConfig.Category != "" {
+			line += "  [" + feedConfig.Category + "]"
+		}
+		if len(feedConfig.Tags) > 0 {
+			line += "  " + strings.Join(feedConfig.Tags, ",")
+		}
+		switch health := state.Health(feedConfig.URL); {
+		case feedConfig.Disabled:
+			line += "  (disabled)"
+		case feedConfig.Paused:
+			line += "  (paused)"
+		case health != nil && health.Failing():
+			line += "  (failing: " + health.LastError + ")"
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	return nil
+}
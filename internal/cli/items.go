@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bloom/internal/feed"
+	"bloom/internal/storage"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+)
+
+// itemJSON is an item in the output of items --json
+type itemJSON struct {
+	ID        string     `json:"id"`
+	Feed      string     `json:"feed"`
+	FeedURL   string     `json:"feed_url"`
+	Title     string     `json:"title"`
+	Link      string     `json:"link"`
+	GUID      string     `json:"guid,omitempty"`
+	Author    string     `json:"author,omitempty"`
+	Published *time.Time `json:"published,omitempty"`
+	Read      bool       `json:"read"`
+}
+
+// published returns when the item was published, zero if unknown
+func (i itemJSON) published() time.Time {
+	if i.Published == nil {
+		return time.Time{}
+	}
+	return *i.Published
+}
+
+// runItems lists the items of the feeds as last fetched. Feeds that have
+// never been fetched are fetched first.
+func runItems(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("items", flag.ContinueOnError)
+	unread := fs.Bool("unread", false, "only list unread items")
+	only := fs.String("feed", "", "only list the items of this feed")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	state, err := storage.LoadState()
+	if err != nil {
+		return err
+	}
+	channels, err := loadChannels(config, *only)
+	if err != nil {
+		return err
+	}
+
+	items := []itemJSON{}
+	for _, channel := range channels {
+		for _, item := range channel.Item {
+			read := state.IsItemRead(item)
+			if *unread && read {
+				continue
+			}
+			feedConfig, _ := config.FindFeed(channel.FeedURL)
+			entry := itemJSON{
+				ID:      item.ID(),
+				Feed:    feedConfig.DisplayTitle(channel.Title),
+				FeedURL: channel.FeedURL,
+				Title:   item.Title,
+				Link:    item.Link,
+				GUID:    item.GUID,
+				Author:  item.Author,
+				Read:    read,
+			}
+			if !item.Published.IsZero() {
+				published := item.Published
+				entry.Published = &published
+			}
+			items = append(items, entry)
+		}
+	}
+
+	// Newest first across feeds
+	slices.SortStableFunc(items, func(a, b itemJSON) int {
+		return feed.ComparePublished(a.published(), b.published())
+	})
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	}
+
+	for _, item := range items {
+		mark := " "
+		if !item.Read {
+			mark = "*"
+		}
+		date := "          "
+		if item.Published != nil {
+			date = item.Published.Local().Format("2006-01-02")
+		}
+		fmt.Fprintf(stdout, "%s %s  %s  %s\n   %s\n", mark, date, item.Feed, item.Title, item.Link)
+	}
+	return nil
+}
+
+// runMarkRead marks items as read by link or ID, or all items of a feed or
+// of every feed
+func runMarkRead(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("mark-read", flag.ContinueOnError)
+	only := fs.String("feed", "", "mark all items of this feed")
+	all := fs.Bool("all", false, "mark all items of every feed")
+	keys, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 && *only == "" && !*all {
+		return fmt.Errorf("expected item links or IDs, --feed or --all")
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	state, err := storage.LoadState()
+	if err != nil {
+		return err
+	}
+	channels, err := loadChannels(config, *only)
+	if err != nil {
+		return err
+	}
+
+	if *only != "" || *all {
+		for _, channel := range channels {
+			for _, item := range channel.Item {
+				keys = append(keys, item.ID())
+			}
+		}
+	}
+
+	marked := state.MarkItems(channels, keys, true)
+	if err := storage.SaveState(state); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "marked %d items as read\n", marked)
+	return nil
+}
+
+// loadChannels returns the enabled feeds, or just feedURL, from the offline
+// cache, fetching those that aren't cached. Feeds that can't be fetched are
+// skipped with a warning, unless feedURL was asked for.
+func loadChannels(config *storage.Config, feedURL string) ([]*feed.Channel, error) {
+	urls, err := selectFeeds(config, feedURL)
+	if err != nil {
+		return nil, err
+	}
+	reader, cache, err := newReader(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var channels []*feed.Channel
+	for _, u := range urls {
+		if entry, ok := cache.Get(u); ok && entry.Channel != nil {
+			entry.Channel.FeedURL = u
+			channels = append(channels, entry.Channel)
+			continue
+		}
+		result, err := reader.Fetch(context.Background(), u)
+		if err != nil && feedURL != "" {
+			return nil, err
+		}
+		if err != nil {
+			// The other feeds are still listed
+			fmt.Fprintf(os.Stderr, "bloom: warning: %s: %v\n", u, err)
+			continue
+		}
+		if result.Channel == nil {
+			continue
+		}
+		result.Channel.FeedURL = u
+		channels = append(channels, result.Channel)
+	}
+	return channels, nil
+}
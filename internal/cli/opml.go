@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"bloom/internal/storage"
+	"fmt"
+	"io"
+)
+
+// runImport adds the feeds from an OPML file to the config
+func runImport(args []string, stdout io.Writer) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected one OPML file")
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	feeds, err := storage.ImportOPMLFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	added := config.MergeFeeds(feeds)
+	if len(added) > 0 {
+		if err := storage.SaveConfig(config); err != nil {
+			return err
+		}
+	}
+
+	for _, feed := range added {
+		fmt.Fprintf(stdout, "added %s\n", feed.URL)
+	}
+	fmt.Fprintf(stdout, "Imported %d of %d feeds\n", len(added), len(feeds))
+	return nil
+}
+
+// runExport writes the configured feeds as OPML to a file or stdout
+func runExport(args []string, stdout io.Writer) error {
+	if len(args) > 1 {
+		return fmt.Errorf("expected at most one output file")
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 || args[0] == "-" {
+		return storage.ExportOPML(config, stdout)
+	}
+	return storage.ExportOPMLFile(config, args[0])
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bloom/internal/feed"
+	"bloom/internal/storage"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+// runRefresh fetches the enabled feeds, or one feed, and records the
+// outcome in the state as the TUI's refresh does
+func runRefresh(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("refresh", flag.ContinueOnError)
+	only := fs.String("feed", "", "refresh only this feed")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	state, err := storage.LoadState()
+	if err != nil {
+		return err
+	}
+	reader, cache, err := newReader(config)
+	if err != nil {
+		return err
+	}
+
+	urls, err := selectFeeds(config, *only)
+	if err != nil {
+		return err
+	}
+
+	configChanged := false
+	var last feed.RefreshProgress
+	for progress := range feed.NewScheduler(reader).Refresh(context.Background(), urls) {
+		last = progress
+		result := progress.Result
+		outcome := storage.UpdateAfterFetch(config, state, result.URL, time.Now(), result.FetchResult, result.Err)
+
+		switch {
+		case result.Err != nil:
+			fmt.Fprintf(stdout, "FAIL  %s: %v\n", result.URL, result.Err)
+		case result.Channel != nil:
+			fmt.Fprintf(stdout, "ok    %s (%d items)\n", result.URL, len(result.Channel.Item))
+		default:
+			fmt.Fprintf(stdout, "ok    %s\n", result.URL)
+		}
+		if outcome.MovedTo != "" {
+			fmt.Fprintf(stdout, "      moved to %s\n", outcome.MovedTo)
+			if err := cache.Move(result.URL, outcome.MovedTo); err != nil {
+				fmt.Fprintf(stdout, "      %v\n", err)
+			}
+		}
+		if outcome.Disabled {
+			fmt.Fprintf(stdout, "      gone, disabled\n")
+		}
+		configChanged = configChanged || outcome.ConfigChanged()
+	}
+
+	state.LastSync = time.Now()
+	if err := storage.SaveState(state); err != nil {
+		return err
+	}
+	if configChanged {
+		if err := storage.SaveConfig(config); err != nil {
+			return err
+		}
+	}
+
+	if last.Failed > 0 {
+		return fmt.Errorf("%d of %d feeds failed", last.Failed, last.Total)
+	}
+	return nil
+}
+
+// selectFeeds returns the URLs of the enabled feeds, or just feedURL if
+// it's given
+func selectFeeds(config *storage.Config, feedURL string) ([]string, error) {
+	if feedURL != "" {
+		feedURL = storage.NormalizeFeedURL(feedURL)
+		for _, feedConfig := range config.Feeds {
+			if feedConfig.URL == feedURL {
+				return []string{feedURL}, nil
+			}
+		}
+		return nil, fmt.Errorf("not subscribed to %s", feedURL)
+	}
+
+	var urls []string
+	for _, feedConfig := range config.Feeds {
+		if feedConfig.Enabled() {
+			urls = append(urls, feedConfig.URL)
+		}
+	}
+	return urls, nil
+}
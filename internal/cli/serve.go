@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"bloom/internal/server"
+	"bloom/internal/storage"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// defaultAddr is where serve listens without --addr or --socket
+const defaultAddr = "127.0.0.1:7070"
+
+// runServe serves the HTTP/JSON API until interrupted
+func runServe(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", defaultAddr, "TCP address to listen on")
+	socket := flags.String("socket", "", "unix socket to listen on instead of TCP")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	state, err := storage.LoadState()
+	if err != nil {
+		return err
+	}
+	srv, err := server.New(config, state)
+	if err != nil {
+		return err
+	}
+
+	var l net.Listener
+	if *socket != "" {
+		// A socket left behind by a previous run would make Listen fail
+		if err := os.Remove(*socket); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove old socket: %v", err)
+		}
+		l, err = net.Listen("unix", *socket)
+	} else {
+		l, err = net.Listen("tcp", *addr)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "serving on %s\n", l.Addr())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return srv.Serve(ctx, l)
+}
@@ -5,21 +5,63 @@ import (
 	readability "github.com/go-shiori/go-readability"
 	"net/http"
 	"net/url"
-	"time"
+	"strings"
+	"sync"
 )
 
 type ArticleFetcher struct {
-	client *http.Client
+	client *Client
+
+	mu          sync.RWMutex
+	feedClients map[string]*Client // Clients of feeds with their own settings, keyed by feed URL
 }
 
 func NewArticleFetcher() *ArticleFetcher {
 	return &ArticleFetcher{
-		client: &http.Client{Timeout: 10 * time.Second},
+		client: mustDefaultClient(),
+	}
+}
+
+// SetClient sets the client articles are fetched with
+func (a *ArticleFetcher) SetClient(client *Client) {
+	a.mu.Lock()
+	a.client = client
+	a.mu.Unlock()
+}
+
+// SetFeedClients replaces the clients used for articles of particular feeds
+func (a *ArticleFetcher) SetFeedClients(clients map[string]*Client) {
+	a.mu.Lock()
+	a.feedClients = clients
+	a.mu.Unlock()
+}
+
+// clientFor returns the client to fetch an article of a feed with. A feed's
+// own client, which may carry its credentials, is only used for articles on
+// the feed's host.
+func (a *ArticleFetcher) clientFor(feedURL string, articleURL *url.URL) *Client {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	client, ok := a.feedClients[feedURL]
+	if !ok || !strings.EqualFold(hostOf(feedURL), articleURL.Host) {
+		return a.client
 	}
+	return client
 }
 
 func (a *ArticleFetcher) Extract(u string) (Article, error) {
-	resp, err := a.client.Get(u)
+	return a.ExtractFromFeed("", u)
+}
+
+// ExtractFromFeed is like Extract, using the settings and credentials of
+// the feed the article came from
+func (a *ArticleFetcher) ExtractFromFeed(feedURL string, u string) (Article, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return Article{}, err
+	}
+
+	resp, err := a.clientFor(feedURL, req.URL).Do(req)
 	if err != nil {
 		return Article{}, err
 	}
@@ -49,3 +91,22 @@ func (a *ArticleFetcher) Extract(u string) (Article, error) {
 	}, nil
 
 }
+
+// Article returns the content the feed includes for the item, its full
+// content or else its summary, as an article
+func (i Item) Article() (Article, error) {
+	content := i.Content
+	if content == "" {
+		content = i.Description
+	}
+	markdown, err := htmltomarkdown.ConvertString(content)
+	if err != nil {
+		return Article{}, err
+	}
+	return Article{
+		Title:   i.Title,
+		Content: markdown,
+		Author:  i.Author,
+		URL:     i.Link,
+	}, nil
+}
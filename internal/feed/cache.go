@@ -0,0 +1,18 @@
+package feed
+
+import "time"
+
+// CacheEntry is what the reader remembers about a feed between fetches
+type CacheEntry struct {
+	ETag         string
+	LastModified string
+	FetchedAt    time.Time
+	Channel      *Channel
+}
+
+// Cache stores the last successful response for each feed URL so the reader
+// can send conditional requests and reuse the channel on 304 Not Modified
+type Cache interface {
+	Get(feedURL string) (*CacheEntry, bool)
+	Put(feedURL string, entry *CacheEntry) error
+}
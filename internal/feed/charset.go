@@ -0,0 +1,82 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"mime"
+	"regexp"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+var xmlDeclEncoding = regexp.MustCompile(`^<\?xml[^>]*?encoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+// toUTF8 transcodes a feed body to UTF-8. The charset is taken from a byte
+// order mark, then the Content-Type header, then the XML declaration; a label
+// that doesn't match the bytes (a server claiming UTF-8 for a Latin-1 file)
+// falls through to the next candidate.
+func toUTF8(body []byte, contentType string) []byte {
+	candidates := []string{bomCharset(body), contentTypeCharset(contentType), declaredCharset(body)}
+
+	for _, label := range candidates {
+		if label == "" {
+			continue
+		}
+		enc, name := charset.Lookup(label)
+		if enc == nil {
+			continue
+		}
+		if name == "utf-8" {
+			if utf8.Valid(body) {
+				return bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))
+			}
+			continue
+		}
+		decoded, err := enc.NewDecoder().Bytes(body)
+		if err == nil {
+			return bytes.TrimPrefix(decoded, []byte("\xef\xbb\xbf"))
+		}
+	}
+
+	return bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))
+}
+
+// newXMLDecoder returns a decoder for a body already transcoded by toUTF8.
+// The XML declaration may still name the original encoding, so the charset
+// reader passes the input through untouched.
+func newXMLDecoder(body []byte) *xml.Decoder {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	d.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return d
+}
+
+func bomCharset(body []byte) string {
+	switch {
+	case bytes.HasPrefix(body, []byte("\xef\xbb\xbf")):
+		return "utf-8"
+	case bytes.HasPrefix(body, []byte("\xfe\xff")):
+		return "utf-16be"
+	case bytes.HasPrefix(body, []byte("\xff\xfe")):
+		return "utf-16le"
+	}
+	return ""
+}
+
+func contentTypeCharset(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
+
+func declaredCharset(body []byte) string {
+	if m := xmlDeclEncoding.FindSubmatch(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
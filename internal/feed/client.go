@@ -0,0 +1,243 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults for ClientOptions fields left zero
+const (
+	DefaultUserAgent      = "bloom/1.0 (+https://github.com/zepzeper/bloom)"
+	DefaultTimeout        = 10 * time.Second
+	DefaultConnectTimeout = 5 * time.Second
+	DefaultMaxRetries     = 2
+	DefaultRetryBackoff   = time.Second
+	DefaultMaxRetryDelay  = 30 * time.Second
+)
+
+// ProxySchemes are the URL schemes ClientOptions.Proxy may use
+var ProxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ClientOptions configures a Client. Zero fields take the defaults above.
+type ClientOptions struct {
+	UserAgent      string
+	Proxy          string        // URL with one of ProxySchemes; empty uses HTTP_PROXY and friends
+	Timeout        time.Duration // For the whole request, including reading the body
+	ConnectTimeout time.Duration
+	MaxRetries     int           // Negative disables retries
+	RetryBackoff   time.Duration // Wait before the first retry, doubled for each one after
+	MaxRetryDelay  time.Duration // Longest wait before a retry; a longer Retry-After isn't retried
+
+	// Credentials, if set, add authentication and custom headers to requests
+	Credentials Credentials
+}
+
+// Credentials supplies the headers added to a feed's requests
+// (Authorization, Cookie, custom headers). Header is called for every
+// request, so implementations should resolve secrets once and cache them.
+type Credentials interface {
+	Header() (http.Header, error)
+}
+
+func (o ClientOptions) withDefaults() ClientOptions {
+	if o.UserAgent == "" {
+		o.UserAgent = DefaultUserAgent
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.ConnectTimeout <= 0 {
+		o.ConnectTimeout = DefaultConnectTimeout
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = DefaultMaxRetries
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = DefaultRetryBackoff
+	}
+	if o.MaxRetryDelay <= 0 {
+		o.MaxRetryDelay = DefaultMaxRetryDelay
+	}
+	return o
+}
+
+// ClientFactory creates the HTTP clients used to fetch feeds and articles.
+// Clients with the same proxy and connect timeout share a transport, and
+// with it their pool of connections.
+type ClientFactory struct {
+	mu         sync.Mutex
+	transports map[transportKey]*http.Transport
+}
+
+type transportKey struct {
+	proxy          string
+	connectTimeout time.Duration
+}
+
+// defaultFactory backs the clients of NewReader and NewArticleFetcher
+var defaultFactory = NewClientFactory()
+
+// NewClientFactory creates a factory with no transports yet
+func NewClientFactory() *ClientFactory {
+	return &ClientFactory{transports: make(map[transportKey]*http.Transport)}
+}
+
+// Client returns a client configured by opts. An invalid proxy URL is
+// reported here rather than on every request.
+func (f *ClientFactory) Client(opts ClientOptions) (*Client, error) {
+	opts = opts.withDefaults()
+
+	transport, err := f.transport(transportKey{proxy: opts.Proxy, connectTimeout: opts.ConnectTimeout})
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{options: opts}
+	client.http = &http.Client{Transport: transport, Timeout: opts.Timeout, CheckRedirect: client.checkRedirect}
+	return client, nil
+}
+
+func (f *ClientFactory) transport(key transportKey) (*http.Transport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if transport, ok := f.transports[key]; ok {
+		return transport, nil
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if key.proxy != "" {
+		proxyURL, err := url.Parse(key.proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, errors.New("invalid proxy URL: " + key.proxy)
+		}
+		if !slices.Contains(ProxySchemes, proxyURL.Scheme) {
+			return nil, errors.New("unsupported proxy scheme: " + proxyURL.Scheme)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	dialer := &net.Dialer{Timeout: key.connectTimeout, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		Proxy:               proxy,
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		TLSHandshakeTimeout: key.connectTimeout,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: DefaultMaxPerHost,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	f.transports[key] = transport
+	return transport, nil
+}
+
+// Client sends GET requests with the configured user agent and retries
+// network errors and 429/502/503/504 responses with exponential backoff,
+// honoring Retry-After
+type Client struct {
+	http    *http.Client
+	options ClientOptions
+}
+
+// mustDefaultClient returns a client with default options
+func mustDefaultClient() *Client {
+	client, err := defaultFactory.Client(ClientOptions{})
+	if err != nil {
+		panic(err) // The default options have no proxy to get wrong
+	}
+	return client
+}
+
+// Do sends the request, retrying as configured. Requests must not have a
+// body. After the last retry the final response or error is returned.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.options.UserAgent)
+	}
+	if c.options.Credentials != nil {
+		header, err := c.options.Credentials.Header()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve credentials: %w", err)
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+	}
+
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if chain := redirectChainFrom(ctx); chain != nil {
+			chain.hops = nil // Only the last attempt's redirects count
+		}
+		resp, err := c.http.Do(req.Clone(ctx))
+
+		if attempt >= c.options.MaxRetries || ctx.Err() != nil {
+			return resp, err
+		}
+		wait, retry := c.retryDelay(attempt, resp, err)
+		if !retry {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay decides whether a failed attempt is retried and after how long
+func (c *Client) retryDelay(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	backoff := c.options.RetryBackoff << attempt
+	if err != nil {
+		return min(backoff, c.options.MaxRetryDelay), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			// Don't sit on a request for longer than the server is worth
+			return wait, wait <= c.options.MaxRetryDelay
+		}
+		return min(backoff, c.options.MaxRetryDelay), true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return min(backoff, c.options.MaxRetryDelay), true
+	}
+	return 0, false
+}
+
+// retryAfter parses a Retry-After header, either delay seconds or an HTTP date
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// sleep waits for d, or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,138 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientRetriesWithRetryAfter(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "test-agent/1.0" {
+			t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+		}
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(readFixture(t, "rss2.xml"))
+	}))
+	defer server.Close()
+
+	client, err := NewClientFactory().Client(ClientOptions{UserAgent: "test-agent/1.0", RetryBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := NewReader()
+	reader.SetClient(client)
+
+	if _, err := reader.Read(server.URL); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("made %d attempts, want 3", attempts)
+	}
+}
+
+func TestClientGivesUp(t *testing.T) {
+	tests := []struct {
+		name         string
+		retryAfter   string
+		opts         ClientOptions
+		wantAttempts int32
+	}{
+		{"retries exhausted", "", ClientOptions{MaxRetries: 2, RetryBackoff: time.Millisecond}, 3},
+		{"retries disabled", "", ClientOptions{MaxRetries: -1}, 1},
+		{"retry-after too long", "3600", ClientOptions{MaxRetries: 2}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			client, err := NewClientFactory().Client(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader := NewReader()
+			reader.SetClient(client)
+
+			result, err := reader.Fetch(context.Background(), server.URL)
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) || result.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("Fetch = %d, %v; want HTTPError 503", result.StatusCode, err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("made %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestClientFactoryRejectsBadProxy(t *testing.T) {
+	factory := NewClientFactory()
+	if _, err := factory.Client(ClientOptions{Proxy: "ftp://proxy.example.com"}); err == nil {
+		t.Error("accepted an ftp proxy")
+	}
+	if _, err := factory.Client(ClientOptions{Proxy: "socks5://127.0.0.1:1080"}); err != nil {
+		t.Errorf("rejected a SOCKS proxy: %v", err)
+	}
+}
+
+// Clients are swapped when the config changes, while fetches may be running
+func TestSetClientWhileFetching(t *testing.T) {
+	reader := NewReader()
+	fetcher := NewArticleFetcher()
+	articleURL := &url.URL{Scheme: "https", Host: "example.com"}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			reader.clientFor("https://example.com/feed")
+			fetcher.clientFor("https://example.com/feed", articleURL)
+		}
+	}()
+	for range 100 {
+		reader.SetClient(mustDefaultClient())
+		fetcher.SetClient(mustDefaultClient())
+	}
+	<-done
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{"Fri, 01 Mar 2024 10:00:30 GMT", 30 * time.Second, true},
+		{"Fri, 01 Mar 2024 09:00:00 GMT", 0, true},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
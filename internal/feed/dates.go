@@ -0,0 +1,120 @@
+package feed
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+)
+
+// dateLayouts are tried before falling back to dateparse. They cover RFC 822
+// (RSS), RFC 3339 (Atom, JSON Feed) and their most common sloppy variants.
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	time.RFC3339Nano,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"Mon, 02 Jan 06 15:04:05 -0700",
+	"Mon, 02 Jan 06 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// zoneOffsets resolves North American zone abbreviations, which Go parses
+// with a zero offset unless they happen to match the local zone
+var zoneOffsets = map[string]int{
+	"EST": -5, "EDT": -4,
+	"CST": -6, "CDT": -5,
+	"MST": -7, "MDT": -6,
+	"PST": -8, "PDT": -7,
+}
+
+// dayNameFixes rewrites day names that don't match Go's three-letter form
+var dayNameFixes = strings.NewReplacer(
+	"Tues,", "Tue,", "Wednes,", "Wed,", "Thurs,", "Thu,", "Thur,", "Thu,",
+	"Sept ", "Sep ",
+)
+
+// ParseDate parses a feed date string, returning the zero time if the format
+// isn't recognized
+func ParseDate(s string) time.Time {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return time.Time{}
+	}
+	s = dayNameFixes.Replace(s)
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return fixZone(t)
+		}
+	}
+
+	// Some publishers get the day of the week wrong; drop it and retry
+	if comma := strings.Index(s, ", "); comma > 0 && comma <= 9 {
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(strings.TrimPrefix(layout, "Mon, "), s[comma+2:]); err == nil {
+				return fixZone(t)
+			}
+		}
+	}
+
+	if t, err := dateparse.ParseAny(s); err == nil {
+		return fixZone(t)
+	}
+
+	return time.Time{}
+}
+
+// fixZone applies the real offset for a parsed zone abbreviation
+func fixZone(t time.Time) time.Time {
+	name, offset := t.Zone()
+	if hours, ok := zoneOffsets[name]; ok && offset != hours*3600 {
+		wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+		return wall.Add(-time.Duration(hours) * time.Hour).In(time.FixedZone(name, hours*3600))
+	}
+	return t
+}
+
+// dateItems parses PubDate into Published for items that don't have it yet
+func dateItems(items []Item) {
+	for i := range items {
+		if items[i].Published.IsZero() {
+			items[i].Published = ParseDate(items[i].PubDate)
+		}
+	}
+}
+
+// SortItems orders items newest first. Items without a date keep their
+// relative order after the dated ones.
+func SortItems(items []Item) {
+	slices.SortStableFunc(items, func(a, b Item) int {
+		return ComparePublished(a.Published, b.Published)
+	})
+}
+
+// ComparePublished orders publication dates as SortItems does, for sorting
+// items merged from several feeds: newest first, unknown (zero) dates last
+func ComparePublished(a, b time.Time) int {
+	switch {
+	case a.IsZero() && b.IsZero():
+		return 0
+	case a.IsZero():
+		return 1
+	case b.IsZero():
+		return -1
+	}
+	return b.Compare(a)
+}
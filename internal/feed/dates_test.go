@@ -0,0 +1,55 @@
+package feed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []string{
+		"Mon, 02 Jan 2006 15:04:05 GMT",
+		"Mon, 02 Jan 2006 15:04:05 +0000",
+		"Mon, 2 Jan 2006 15:04:05 +0000",
+		"Mon, 02 Jan 2006 10:04:05 EST",
+		"Mon, 02 Jan 2006 08:04:05 PDT",
+		"Mon, 02 Jan 06 15:04:05 +0000",
+		"Tues, 02 Jan 2006 15:04:05 GMT", // nonstandard day name
+		"Fri, 02 Jan 2006 15:04:05 GMT",  // wrong day of the week
+		"  Mon,  02 Jan 2006\n15:04:05 GMT ",
+		"2006-01-02T15:04:05Z",
+		"2006-01-02T17:04:05+02:00",
+		"2006-01-02T15:04:05.000Z",
+		"2006-01-02T15:04:05+0000",
+		"2006-01-02 15:04:05",
+	}
+	for _, s := range tests {
+		if got := ParseDate(s); !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %v, want %v", s, got, want)
+		}
+	}
+
+	for _, s := range []string{"", "not a date", "yesterday"} {
+		if got := ParseDate(s); !got.IsZero() {
+			t.Errorf("ParseDate(%q) = %v, want zero time", s, got)
+		}
+	}
+}
+
+func TestSortItems(t *testing.T) {
+	items := []Item{
+		{Title: "undated a"},
+		{Title: "old", Published: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "undated b"},
+		{Title: "new", Published: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	SortItems(items)
+
+	want := []string{"new", "old", "undated a", "undated b"}
+	for i, title := range want {
+		if items[i].Title != title {
+			t.Fatalf("order = %v, want %v", items, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"mime"
+	"strings"
+)
+
+// feedFormat identifies the syndication format of a feed document
+type feedFormat int
+
+const (
+	formatUnknown feedFormat = iota
+	formatRSS
+	formatAtom
+	formatRDF
+	formatJSON
+)
+
+// detectFormat determines the feed format from the body's first meaningful
+// token: an opening brace for JSON, or the name of the XML document element.
+// The Content-Type header is only consulted when the body doesn't give it
+// away. The body must already be UTF-8 (see toUTF8).
+func detectFormat(contentType string, body []byte) feedFormat {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+
+	if len(trimmed) > 0 {
+		switch trimmed[0] {
+		case '{':
+			return formatJSON
+		case '<':
+			root := xmlRootElement(trimmed)
+			switch {
+			case root.Local == "feed":
+				return formatAtom
+			case root.Local == "rss" && root.Space == "":
+				return formatRSS
+			case root.Local == "RDF" && root.Space == nsRDF:
+				return formatRDF
+			}
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/feed+json" || strings.HasSuffix(mediaType, "json"):
+		return formatJSON
+	case mediaType == "application/atom+xml":
+		return formatAtom
+	case mediaType == "application/rss+xml":
+		return formatRSS
+	case mediaType == "application/rdf+xml":
+		return formatRDF
+	}
+
+	return formatUnknown
+}
+
+// xmlRootElement returns the namespace-resolved name of the document element.
+// It tokenizes the prologue (declaration, comments, doctype) rather than
+// searching the text, so markup mentioned inside items can't mislead it.
+func xmlRootElement(body []byte) xml.Name {
+	d := newXMLDecoder(body)
+	d.Strict = false
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return xml.Name{}
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return canonicalName(start.Name)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package feed
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DiscoveredFeed is a feed advertised by, or found next to, a web page
+type DiscoveredFeed struct {
+	URL   string
+	Title string
+	Type  string // MIME type, e.g. application/atom+xml
+}
+
+// feedLinkTypes are the <link rel="alternate"> types that point at feeds
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/rdf+xml":   true,
+}
+
+// commonFeedPaths are probed when a page doesn't advertise its feeds
+var commonFeedPaths = []string{"/feed", "/rss.xml", "/atom.xml", "/feed.xml", "/index.xml", "/rss", "/feed.json"}
+
+// maxDiscoveryBody caps how much of a page is read while looking for feeds
+const maxDiscoveryBody = 5 << 20
+
+// Discover finds the feeds for a URL. A URL that already points at a feed is
+// returned as-is. Otherwise the page's <link rel="alternate"> entries are
+// used, and failing that a few common feed paths on the site are probed.
+func (r *Reader) Discover(pageURL string) ([]DiscoveredFeed, error) {
+	return r.DiscoverWith(nil, pageURL)
+}
+
+// DiscoverWith is Discover fetching with the given client, for sites that
+// need credentials. A nil client uses the reader's client for the URL.
+func (r *Reader) DiscoverWith(client *Client, pageURL string) ([]DiscoveredFeed, error) {
+	if client == nil {
+		client = r.clientFor(pageURL)
+	}
+	body, contentType, finalURL, err := fetchPage(client, pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s: %w", pageURL, err)
+	}
+
+	// The URL is a feed itself
+	if found, ok := r.identifyFeed(body, contentType, finalURL); ok {
+		return []DiscoveredFeed{found}, nil
+	}
+
+	feeds, err := feedLinks(body, finalURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", pageURL, err)
+	}
+	if len(feeds) > 0 {
+		return feeds, nil
+	}
+
+	feeds = r.probeCommonPaths(client, finalURL)
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("no feeds found at %s", pageURL)
+	}
+	return feeds, nil
+}
+
+// fetchPage downloads a page and returns its body, content type and the URL
+// it was finally served from after redirects
+func fetchPage(client *Client, pageURL string) ([]byte, string, *url.URL, error) {
+	req, err := http.NewRequest(http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	req.Header.Set("Accept", "text/html, "+acceptHeader)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDiscoveryBody))
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return body, resp.Header.Get("Content-Type"), resp.Request.URL, nil
+}
+
+// identifyFeed reports whether a response body is a feed we can parse
+func (r *Reader) identifyFeed(body []byte, contentType string, feedURL *url.URL) (DiscoveredFeed, bool) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "text/html" {
+		return DiscoveredFeed{}, false
+	}
+	if detectFormat(contentType, toUTF8(body, contentType)) == formatUnknown {
+		return DiscoveredFeed{}, false
+	}
+
+	channel, err := r.parse(body, contentType, feedURL.String())
+	if err != nil {
+		return DiscoveredFeed{}, false
+	}
+
+	return DiscoveredFeed{URL: feedURL.String(), Title: channel.Title, Type: mediaType}, true
+}
+
+// feedLinks extracts feed links from an HTML page's head
+func feedLinks(body []byte, pageURL *url.URL) ([]DiscoveredFeed, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(toUTF8(body, ""))))
+	if err != nil {
+		return nil, err
+	}
+
+	// Relative links resolve against <base href> when the page sets one
+	base := pageURL
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if u, err := pageURL.Parse(href); err == nil {
+			base = u
+		}
+	}
+
+	var feeds []DiscoveredFeed
+	seen := map[string]bool{}
+	doc.Find("link[rel][href]").Each(func(_ int, s *goquery.Selection) {
+		rel := strings.Fields(strings.ToLower(s.AttrOr("rel", "")))
+		isAlternate := false
+		for _, r := range rel {
+			if r == "alternate" {
+				isAlternate = true
+			}
+		}
+
+		linkType := strings.ToLower(strings.TrimSpace(s.AttrOr("type", "")))
+		if !isAlternate || !feedLinkTypes[linkType] {
+			return
+		}
+
+		u, err := base.Parse(strings.TrimSpace(s.AttrOr("href", "")))
+		if err != nil || seen[u.String()] {
+			return
+		}
+		seen[u.String()] = true
+
+		feeds = append(feeds, DiscoveredFeed{
+			URL:   u.String(),
+			Title: strings.TrimSpace(s.AttrOr("title", "")),
+			Type:  linkType,
+		})
+	})
+
+	return feeds, nil
+}
+
+// probeCommonPaths tries the usual feed locations on the page's site
+func (r *Reader) probeCommonPaths(client *Client, pageURL *url.URL) []DiscoveredFeed {
+	results := make([]*DiscoveredFeed, len(commonFeedPaths))
+
+	var wg sync.WaitGroup
+	for i, path := range commonFeedPaths {
+		candidate := &url.URL{Scheme: pageURL.Scheme, Host: pageURL.Host, Path: path}
+
+		wg.Add(1)
+		go func(i int, candidate string) {
+			defer wg.Done()
+			body, contentType, finalURL, err := fetchPage(client, candidate)
+			if err != nil {
+				return
+			}
+			if found, ok := r.identifyFeed(body, contentType, finalURL); ok {
+				results[i] = &found
+			}
+		}(i, candidate.String())
+	}
+	wg.Wait()
+
+	// Several paths often redirect to the same feed
+	var feeds []DiscoveredFeed
+	seen := map[string]bool{}
+	for _, found := range results {
+		if found != nil && !seen[found.URL] {
+			seen[found.URL] = true
+			feeds = append(feeds, *found)
+		}
+	}
+	return feeds
+}
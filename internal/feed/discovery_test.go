@@ -0,0 +1,83 @@
+package feed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDiscoverFromLinkTags(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<!doctype html><html><head>
+<link rel="stylesheet" href="/style.css">
+<link rel="alternate" type="application/rss+xml" title="Posts" href="/posts.rss">
+<link rel="alternate" type="application/atom+xml" title="Comments" href="https://other.example/comments.atom">
+<link rel="alternate" type="application/rss+xml" href="/posts.rss">
+</head><body>Hello</body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	feeds, err := NewReader().Discover(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(feeds) != 2 {
+		t.Fatalf("got %d feeds, want 2: %+v", len(feeds), feeds)
+	}
+	if feeds[0].URL != server.URL+"/posts.rss" || feeds[0].Title != "Posts" {
+		t.Errorf("first feed = %+v", feeds[0])
+	}
+	if feeds[1].URL != "https://other.example/comments.atom" {
+		t.Errorf("second feed = %+v", feeds[1])
+	}
+}
+
+func TestDiscoverFeedURL(t *testing.T) {
+	body := readFixture(t, "atom.xml")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/atom+xml")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	feeds, err := NewReader().Discover(server.URL + "/atom.xml")
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].URL != server.URL+"/atom.xml" || feeds[0].Title != "Example Atom" {
+		t.Errorf("feeds = %+v", feeds)
+	}
+}
+
+func TestDiscoverProbesCommonPaths(t *testing.T) {
+	body := readFixture(t, "rss2.xml")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>No links</title></head></html>`))
+	})
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/feed.xml", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(body)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	feeds, err := NewReader().Discover(server.URL)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].URL != server.URL+"/feed.xml" {
+		t.Errorf("feeds = %+v, want only %s/feed.xml", feeds, server.URL)
+	}
+}
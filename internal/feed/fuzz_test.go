@@ -0,0 +1,70 @@
+package feed
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+)
+
+// addFixtureSeeds seeds a fuzz corpus with every file in testdata
+func addFixtureSeeds(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		f.Add(data)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	addFixtureSeeds(f)
+	f.Add([]byte(`<rss><channel><item><title>&</title>`))
+	f.Add([]byte(`{"version":"https://jsonfeed.org/version/1.1","items":[{"id":1}]}`))
+
+	r := &Reader{}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		channel, err := r.parse(data, "", "https://fuzz.example/feed")
+		if err == nil && channel == nil {
+			t.Fatal("parse returned neither a channel nor an error")
+		}
+	})
+}
+
+func FuzzParseXMLFormats(f *testing.F) {
+	addFixtureSeeds(f)
+
+	r := &Reader{}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, format := range []feedFormat{formatRSS, formatAtom, formatRDF} {
+			for _, d := range []*xml.Decoder{newXMLDecoder(data), newLenientXMLDecoder(data)} {
+				channel, err := r.parseXML(format, d, "https://fuzz.example/feed")
+				if err == nil && channel == nil {
+					t.Fatalf("parsing as %v returned neither a channel nor an error", format)
+				}
+			}
+		}
+	})
+}
+
+func FuzzSanitizeXML(f *testing.F) {
+	addFixtureSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		out := sanitizeXML(data)
+		if !utf8.Valid(out) {
+			t.Fatalf("sanitizeXML produced invalid UTF-8: %q", out)
+		}
+		for _, r := range string(out) {
+			if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+				t.Fatalf("sanitizeXML kept control character %U", r)
+			}
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package feed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that only identify how a link was
+// shared, dropped by NormalizeLink. Keys ending in "_" match as prefixes.
+var trackingParams = []string{
+	"utm_", "mc_", "pk_", "_hsenc", "_hsmi", "fbclid", "gclid", "dclid", "msclkid",
+	"yclid", "igshid", "mkt_tok", "ref_src", "ref_url",
+}
+
+// ID returns a stable identifier for the item: its guid or Atom id, then
+// its link normalized with NormalizeLink, then a hash of its content
+func (i Item) ID() string {
+	if guid := strings.TrimSpace(i.GUID); guid != "" {
+		// Permalink guids suffer from the same drift as links
+		if isWebURL(guid) {
+			return NormalizeLink(guid)
+		}
+		return guid
+	}
+	if link := strings.TrimSpace(i.Link); link != "" {
+		return NormalizeLink(link)
+	}
+
+	h := sha256.New()
+	for _, s := range []string{i.Title, i.PubDate, i.Description, i.Content} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// NormalizeLink returns a canonical form of an article URL, so the same
+// article is recognised when a publisher switches between http and https,
+// adds tracking parameters or reorders the query. Fragments are kept, as
+// feeds such as changelogs link each entry to an anchor of one page, unless
+// they hold parameters rather than an anchor ID. Links that aren't http(s)
+// URLs are returned trimmed.
+func NormalizeLink(link string) string {
+	link = strings.TrimSpace(link)
+	u, err := url.Parse(link)
+	if err != nil || !isWebURL(link) || u.Host == "" {
+		return link
+	}
+
+	u.Scheme = "https"
+	u.Host = strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(u.Host, ":80"), ":443"))
+	if strings.Contains(u.Fragment, "=") {
+		// Tracking or app state, like #utm_source=rss or #xtor=RSS-1
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	query := u.Query()
+	for key := range query {
+		if isTrackingParam(key) {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode() // Sorted by key
+	return u.String()
+}
+
+// isWebURL reports whether s looks like an http(s) URL
+func isWebURL(s string) bool {
+	lower := strings.ToLower(s)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// isTrackingParam reports whether a query parameter is in trackingParams
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	for _, param := range trackingParams {
+		if key == param || strings.HasSuffix(param, "_") && strings.HasPrefix(key, param) {
+			return true
+		}
+	}
+	return false
+}
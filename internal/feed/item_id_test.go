@@ -0,0 +1,49 @@
+package feed
+
+import "testing"
+
+func TestNormalizeLink(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"http://Example.com/post?utm_source=rss&utm_medium=feed", "https://example.com/post"},
+		{"https://example.com:443/post?b=2&fbclid=x&a=1#xtor=RSS-1", "https://example.com/post?a=1&b=2"},
+		{"http://example.com/changelog#v1.2", "https://example.com/changelog#v1.2"},
+		{"https://example.com", "https://example.com/"},
+		{"  https://example.com/post  ", "https://example.com/post"},
+		{"tag:example.com,2024:post-1", "tag:example.com,2024:post-1"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeLink(tt.link); got != tt.want {
+			t.Errorf("NormalizeLink(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestItemID(t *testing.T) {
+	withGUID := Item{GUID: "urn:uuid:1234", Link: "https://example.com/a?utm_source=x"}
+	if got := withGUID.ID(); got != "urn:uuid:1234" {
+		t.Errorf("expected the guid, got %q", got)
+	}
+
+	// The same article before and after the publisher moved to https and
+	// added tracking parameters
+	before := Item{Link: "http://example.com/a"}
+	after := Item{Link: "https://example.com/a?utm_campaign=feed"}
+	if before.ID() != after.ID() {
+		t.Errorf("IDs differ: %q and %q", before.ID(), after.ID())
+	}
+
+	// Entries of a changelog feed link to anchors of the same page
+	v1 := Item{Link: "https://example.com/changelog#v1.1"}
+	v2 := Item{Link: "https://example.com/changelog#v1.2"}
+	if v1.ID() == v2.ID() {
+		t.Errorf("entries linking to different anchors share the ID %q", v1.ID())
+	}
+
+	noLink := Item{Title: "Note", Content: "Some text"}
+	if id := noLink.ID(); id != noLink.ID() || id == (Item{Title: "Other", Content: "Some text"}).ID() {
+		t.Errorf("content hash isn't stable or distinct: %q", id)
+	}
+}
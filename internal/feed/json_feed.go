@@ -0,0 +1,144 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSON Feed structures (https://jsonfeed.org/version/1.1)
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url"`
+	FeedURL     string           `json:"feed_url"`
+	Description string           `json:"description"`
+	Language    string           `json:"language"`
+	Authors     []jsonFeedAuthor `json:"authors"`
+	Author      *jsonFeedAuthor  `json:"author"` // JSON Feed 1.0
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            jsonFeedID           `json:"id"`
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Authors       []jsonFeedAuthor     `json:"authors"`
+	Author        *jsonFeedAuthor      `json:"author"` // JSON Feed 1.0
+	Tags          []string             `json:"tags"`
+	Attachments   []jsonFeedAttachment `json:"attachments"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type jsonFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	Title       string `json:"title"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+}
+
+// jsonFeedID accepts both string and numeric ids, the latter being a common
+// spec violation
+type jsonFeedID string
+
+func (id *jsonFeedID) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = jsonFeedID(s)
+		return nil
+	}
+	if string(data) == "null" {
+		return nil
+	}
+	*id = jsonFeedID(data)
+	return nil
+}
+
+// authorName returns the first named author, falling back to the 1.0 author field
+func authorName(authors []jsonFeedAuthor, legacy *jsonFeedAuthor) string {
+	for _, author := range authors {
+		if author.Name != "" {
+			return author.Name
+		}
+	}
+	if legacy != nil {
+		return legacy.Name
+	}
+	return ""
+}
+
+func (r *Reader) parseJSONFeed(body []byte, feedURL string) (*Channel, error) {
+	var jf jsonFeed
+	err := json.Unmarshal(body, &jf)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing JSON feed: %w", err)
+	}
+
+	if !strings.HasPrefix(jf.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("error parsing JSON feed: unsupported version %q", jf.Version)
+	}
+
+	channel := &Channel{
+		Title:       jf.Title,
+		Description: jf.Description,
+		Link:        jf.HomePageURL,
+		Language:    jf.Language,
+		FeedURL:     feedURL,
+	}
+
+	feedAuthor := authorName(jf.Authors, jf.Author)
+
+	channel.Item = make([]Item, len(jf.Items))
+	for i, entry := range jf.Items {
+		item := Item{
+			Title:       entry.Title,
+			Link:        entry.URL,
+			Description: entry.Summary,
+			Content:     entry.ContentHTML,
+			GUID:        string(entry.ID),
+			Author:      authorName(entry.Authors, entry.Author),
+			Categories:  entry.Tags,
+			PubDate:     entry.DatePublished,
+		}
+
+		if item.Link == "" {
+			item.Link = entry.ExternalURL
+		}
+		if item.Content == "" {
+			item.Content = entry.ContentText
+		}
+		if item.Author == "" {
+			item.Author = feedAuthor
+		}
+		if item.PubDate == "" {
+			item.PubDate = entry.DateModified
+		}
+
+		for _, attachment := range entry.Attachments {
+			item.Enclosures = append(item.Enclosures, Enclosure{
+				URL:    attachment.URL,
+				Length: attachment.SizeInBytes,
+				Type:   attachment.MimeType,
+			})
+		}
+
+		channel.Item[i] = item
+	}
+
+	return channel, nil
+}
@@ -1,21 +1,54 @@
 package feed
 
+import "time"
+
 type Channel struct {
-	Title       string   `xml:"title"`
-	Description string   `xml:"description"`
-	Link        string   `xml:"link"`        // Website URL from RSS feed
-	Language    string   `xml:"language"`
-	Item        []Item   `xml:"item"`
-	Category    string   `xml:"category"`
-	Tags        []string `xml:"tags"`
-	FeedURL     string   `xml:"-"`            // The feed URL used to fetch this channel (not in XML)
+	Title       string
+	Description string
+	Link        string // Website URL from RSS feed
+	Language    string
+	Item        []Item
+	Category    string
+	Tags        []string
+	FeedURL     string // The feed URL used to fetch this channel
+
+	// Recovered is set when the feed was malformed and only parsed after
+	// sanitizing; RecoveryNote holds the original parse error
+	Recovered    bool
+	RecoveryNote string
+
+	// FetchedAt is when the channel was last fetched from the network.
+	// Stale is set while only the offline cache copy is available.
+	FetchedAt time.Time
+	Stale     bool
+
+	// Polling hints declared by the publisher, zero when absent
+	TTL            time.Duration  // RSS <ttl>
+	SkipHours      []int          // GMT hours not to poll in, from <skipHours>
+	SkipDays       []time.Weekday // Days not to poll on, from <skipDays>
+	UpdateInterval time.Duration  // sy:updatePeriod divided by sy:updateFrequency
 }
 
 type Item struct {
-	Title   string `xml:"title"`
-	Link    string `xml:"link"`
-	PubDate string `xml:"pubDate"`
-	Read    bool   `xml:"-"`
+	Title       string
+	Link        string
+	Description string // Summary or excerpt (RSS description, Atom summary)
+	Content     string // Full content (content:encoded, Atom content)
+	GUID        string // RSS guid or Atom id
+	Author      string // RSS author, dc:creator or Atom author name
+	Categories  []string
+	Comments    string      // URL of the comments page
+	Enclosures  []Enclosure // Attached media (podcasts, images)
+	PubDate     string      // Date as published, for display when it can't be parsed
+	Published   time.Time   // Parsed PubDate, zero if unknown
+	Read        bool
+}
+
+// Enclosure represents a media object attached to an item
+type Enclosure struct {
+	URL    string
+	Length int64
+	Type   string
 }
 
 type Article struct {
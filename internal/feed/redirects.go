@@ -0,0 +1,75 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// maxRedirects matches the limit of Go's default redirect policy
+const maxRedirects = 10
+
+// redirectChain records the redirects followed while fetching a feed
+type redirectChain struct {
+	hops []redirectHop
+}
+
+type redirectHop struct {
+	statusCode int
+	url        string
+}
+
+type redirectChainKey struct{}
+
+// withRedirectChain returns a context that records the redirects of
+// requests made with it
+func withRedirectChain(ctx context.Context) (context.Context, *redirectChain) {
+	chain := &redirectChain{}
+	return context.WithValue(ctx, redirectChainKey{}, chain), chain
+}
+
+func redirectChainFrom(ctx context.Context) *redirectChain {
+	chain, _ := ctx.Value(redirectChainKey{}).(*redirectChain)
+	return chain
+}
+
+// permanentTarget returns where the feed has moved to: the final URL, if
+// it was reached only through permanent (301 or 308) redirects
+func (c *redirectChain) permanentTarget() string {
+	if len(c.hops) == 0 {
+		return ""
+	}
+	for _, hop := range c.hops {
+		if hop.statusCode != http.StatusMovedPermanently && hop.statusCode != http.StatusPermanentRedirect {
+			return ""
+		}
+	}
+	return c.hops[len(c.hops)-1].url
+}
+
+// checkRedirect is the redirect policy of every Client. It records each
+// hop in the request's redirect chain, if it has one. Go only drops
+// Authorization and Cookie on a redirect to another host, so the client's
+// other credential headers, such as API keys, are dropped here.
+func (c *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+	if c.options.Credentials != nil && req.URL.Host != via[0].URL.Host {
+		if header, err := c.options.Credentials.Header(); err == nil {
+			for name := range header {
+				req.Header.Del(name)
+			}
+		}
+	}
+	if chain := redirectChainFrom(req.Context()); chain != nil && req.Response != nil {
+		chain.hops = append(chain.hops, redirectHop{statusCode: req.Response.StatusCode, url: req.URL.String()})
+	}
+	return nil
+}
+
+// IsGone reports whether err says the feed was permanently removed (410 Gone)
+func IsGone(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusGone
+}
@@ -0,0 +1,95 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchReportsPermanentMoves(t *testing.T) {
+	body := readFixture(t, "rss2.xml")
+	mux := http.NewServeMux()
+	mux.Handle("/old", http.RedirectHandler("/older", http.StatusMovedPermanently))
+	mux.Handle("/older", http.RedirectHandler("/new", http.StatusPermanentRedirect))
+	mux.Handle("/temporary", http.RedirectHandler("/new", http.StatusFound))
+	mux.Handle("/mixed", http.RedirectHandler("/temporary", http.StatusMovedPermanently))
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(body)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		path    string
+		movedTo string
+	}{
+		{"/old", server.URL + "/new"},
+		{"/temporary", ""},
+		{"/mixed", ""},
+		{"/new", ""},
+	}
+	for _, tt := range tests {
+		result, err := NewReader().Fetch(context.Background(), server.URL+tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if result.MovedTo != tt.movedTo {
+			t.Errorf("%s: MovedTo = %q, want %q", tt.path, result.MovedTo, tt.movedTo)
+		}
+	}
+
+	_, err := NewReader().Fetch(context.Background(), server.URL+"/gone")
+	if !IsGone(err) {
+		t.Errorf("410 response: IsGone(%v) = false", err)
+	}
+}
+
+// staticCredentials are credentials whose header never changes
+type staticCredentials http.Header
+
+func (c staticCredentials) Header() (http.Header, error) {
+	return http.Header(c), nil
+}
+
+func TestRedirectToAnotherHostDropsCredentials(t *testing.T) {
+	var otherKey, otherAuth string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherKey = r.Header.Get("X-Api-Key")
+		otherAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(readFixture(t, "rss2.xml"))
+	}))
+	defer other.Close()
+
+	var ownKey string
+	own := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ownKey = r.Header.Get("X-Api-Key")
+		http.Redirect(w, r, other.URL+"/feed.xml", http.StatusFound)
+	}))
+	defer own.Close()
+
+	client, err := NewClientFactory().Client(ClientOptions{Credentials: staticCredentials{
+		"X-Api-Key":     {"key-123"},
+		"Authorization": {"Bearer token"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := NewReader()
+	reader.SetClient(client)
+	if _, err := reader.Fetch(context.Background(), own.URL+"/feed.xml"); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	if ownKey != "key-123" {
+		t.Errorf("the feed's own host got X-Api-Key %q", ownKey)
+	}
+	if otherKey != "" || otherAuth != "" {
+		t.Errorf("the other host got X-Api-Key %q, Authorization %q", otherKey, otherAuth)
+	}
+}
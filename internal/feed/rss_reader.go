@@ -1,94 +1,547 @@
 package feed
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 type Reader struct {
-	client *http.Client
+	client *Client
+	cache  Cache
+
+	mu          sync.RWMutex       // Guards client and feedClients, swapped while fetches run
+	feedClients map[string]*Client // Per-feed overrides of client, keyed by feed URL
 }
 
 func NewReader() *Reader {
 	return &Reader{
-		client: &http.Client{Timeout: 10 * time.Second},
+		client: mustDefaultClient(),
+	}
+}
+
+// SetClient sets the client used for feeds without a client of their own
+func (r *Reader) SetClient(client *Client) {
+	r.mu.Lock()
+	r.client = client
+	r.mu.Unlock()
+}
+
+// SetFeedClients replaces the clients used for particular feed URLs
+func (r *Reader) SetFeedClients(clients map[string]*Client) {
+	r.mu.Lock()
+	r.feedClients = clients
+	r.mu.Unlock()
+}
+
+// clientFor returns the client to fetch a feed with
+func (r *Reader) clientFor(feedURL string) *Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if client, ok := r.feedClients[feedURL]; ok {
+		return client
+	}
+	return r.client
+}
+
+// SetCache enables conditional requests backed by the given cache
+func (r *Reader) SetCache(cache Cache) {
+	r.cache = cache
+}
+
+// XML namespaces used by RSS extension modules and Atom
+const (
+	nsAtom    = "http://www.w3.org/2005/Atom"
+	nsContent = "http://purl.org/rss/1.0/modules/content/"
+	nsDC      = "http://purl.org/dc/elements/1.1/"
+	nsRDF     = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsRSS10   = "http://purl.org/rss/1.0/"
+	nsRSS090  = "http://my.netscape.com/rdf/simple/0.9/"
+	nsSy      = "http://purl.org/rss/1.0/modules/syndication/"
+)
+
+// knownPrefixes maps conventional prefixes to their namespace, for feeds that
+// use a prefix without declaring it
+var knownPrefixes = map[string]string{
+	"atom":    nsAtom,
+	"content": nsContent,
+	"dc":      nsDC,
+	"rdf":     nsRDF,
+	"sy":      nsSy,
+}
+
+// canonicalName resolves undeclared but well-known prefixes to their namespace.
+// The RSS 1.0 and 0.90 default namespaces are folded into the empty one so RDF
+// channels and items decode with the same code as RSS 2.0.
+func canonicalName(name xml.Name) xml.Name {
+	if ns, ok := knownPrefixes[name.Space]; ok {
+		name.Space = ns
+	}
+	if name.Space == nsRSS10 || name.Space == nsRSS090 {
+		name.Space = ""
 	}
+	return name
 }
 
 type rssFeed struct {
-	Channel Channel `xml:"channel"`
+	XMLName xml.Name   `xml:"rss"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// rdfFeed is an RSS 1.0 (or 0.90) document, where items are siblings of the
+// channel rather than its children
+type rdfFeed struct {
+	XMLName xml.Name   `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# RDF"`
+	Channel rssChannel `xml:"channel"`
+	Items   []rssItem  `xml:"item"`
+}
+
+// rssChannel is decoded element by element so that namespaced elements
+// (atom:link, itunes:author, ...) don't clobber their RSS counterparts
+type rssChannel struct {
+	Title       string
+	Description string
+	Link        string
+	Language    string
+	Category    string
+	Items       []rssItem
+
+	// Polling hints
+	TTL             string
+	SkipHours       []string
+	SkipDays        []string
+	UpdatePeriod    string
+	UpdateFrequency string
+}
+
+type rssItem struct {
+	About       string // rdf:about, the item's URI in RSS 1.0
+	Title       string
+	Link        string
+	Description string
+	Content     string
+	GUID        string
+	Author      string
+	Creator     string
+	Categories  []string
+	Subjects    []string
+	Comments    string
+	Enclosures  []rssEnclosure
+	PubDate     string
+	Date        string
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+func (c *rssChannel) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch canonicalName(t.Name) {
+			case xml.Name{Local: "title"}:
+				err = d.DecodeElement(&c.Title, &t)
+			case xml.Name{Local: "description"}:
+				err = d.DecodeElement(&c.Description, &t)
+			case xml.Name{Local: "link"}:
+				err = d.DecodeElement(&c.Link, &t)
+			case xml.Name{Local: "language"}:
+				err = d.DecodeElement(&c.Language, &t)
+			case xml.Name{Space: nsDC, Local: "language"}:
+				var language string
+				err = d.DecodeElement(&language, &t)
+				if c.Language == "" {
+					c.Language = language
+				}
+			case xml.Name{Local: "category"}:
+				err = d.DecodeElement(&c.Category, &t)
+			case xml.Name{Local: "ttl"}:
+				err = d.DecodeElement(&c.TTL, &t)
+			case xml.Name{Local: "skipHours"}:
+				var skip struct {
+					Hours []string `xml:"hour"`
+				}
+				err = d.DecodeElement(&skip, &t)
+				c.SkipHours = append(c.SkipHours, skip.Hours...)
+			case xml.Name{Local: "skipDays"}:
+				var skip struct {
+					Days []string `xml:"day"`
+				}
+				err = d.DecodeElement(&skip, &t)
+				c.SkipDays = append(c.SkipDays, skip.Days...)
+			case xml.Name{Space: nsSy, Local: "updatePeriod"}:
+				err = d.DecodeElement(&c.UpdatePeriod, &t)
+			case xml.Name{Space: nsSy, Local: "updateFrequency"}:
+				err = d.DecodeElement(&c.UpdateFrequency, &t)
+			case xml.Name{Local: "item"}:
+				var item rssItem
+				err = d.DecodeElement(&item, &t)
+				c.Items = append(c.Items, item)
+			default:
+				err = d.Skip()
+			}
+			if err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (it *rssItem) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if canonicalName(attr.Name) == (xml.Name{Space: nsRDF, Local: "about"}) {
+			it.About = attr.Value
+		}
+	}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch canonicalName(t.Name) {
+			case xml.Name{Local: "title"}:
+				err = d.DecodeElement(&it.Title, &t)
+			case xml.Name{Local: "link"}:
+				err = d.DecodeElement(&it.Link, &t)
+			case xml.Name{Local: "description"}:
+				err = d.DecodeElement(&it.Description, &t)
+			case xml.Name{Space: nsContent, Local: "encoded"}:
+				err = d.DecodeElement(&it.Content, &t)
+			case xml.Name{Local: "guid"}:
+				err = d.DecodeElement(&it.GUID, &t)
+			case xml.Name{Local: "author"}:
+				err = d.DecodeElement(&it.Author, &t)
+			case xml.Name{Space: nsDC, Local: "creator"}:
+				err = d.DecodeElement(&it.Creator, &t)
+			case xml.Name{Local: "category"}:
+				var category string
+				err = d.DecodeElement(&category, &t)
+				it.Categories = append(it.Categories, category)
+			case xml.Name{Local: "comments"}:
+				err = d.DecodeElement(&it.Comments, &t)
+			case xml.Name{Local: "enclosure"}:
+				var enclosure rssEnclosure
+				err = d.DecodeElement(&enclosure, &t)
+				it.Enclosures = append(it.Enclosures, enclosure)
+			case xml.Name{Local: "pubDate"}:
+				err = d.DecodeElement(&it.PubDate, &t)
+			case xml.Name{Space: nsDC, Local: "date"}:
+				err = d.DecodeElement(&it.Date, &t)
+			case xml.Name{Space: nsDC, Local: "subject"}:
+				var subject string
+				err = d.DecodeElement(&subject, &t)
+				it.Subjects = append(it.Subjects, subject)
+			default:
+				err = d.Skip()
+			}
+			if err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
 }
 
 // Atom feed structures
 type atomFeed struct {
-	XMLName xml.Name    `xml:"feed"`
-	Title   string      `xml:"title"`
-	Subtitle string     `xml:"subtitle"`
-	Link    []atomLink  `xml:"link"`
-	ID      string      `xml:"id"`
-	Updated string      `xml:"updated"`
-	Entry   []atomEntry `xml:"entry"`
+	XMLName  xml.Name     `xml:"feed"`
+	Title    string       `xml:"title"`
+	Subtitle string       `xml:"subtitle"`
+	Link     []atomLink   `xml:"link"`
+	ID       string       `xml:"id"`
+	Updated  string       `xml:"updated"`
+	Author   []atomPerson `xml:"author"`
+	Entry    []atomEntry  `xml:"entry"`
 }
 
 type atomEntry struct {
-	Title   string     `xml:"title"`
-	Link    []atomLink `xml:"link"`
-	ID      string     `xml:"id"`
-	Updated string     `xml:"updated"`
-	Published string   `xml:"published"`
-	Summary string     `xml:"summary"`
-	Content string     `xml:"content"`
+	Title     string         `xml:"title"`
+	Link      []atomLink     `xml:"link"`
+	ID        string         `xml:"id"`
+	Updated   string         `xml:"updated"`
+	Published string         `xml:"published"`
+	Author    []atomPerson   `xml:"author"`
+	Category  []atomCategory `xml:"category"`
+	Summary   atomText       `xml:"summary"`
+	Content   atomText       `xml:"content"`
 }
 
 type atomLink struct {
-	Href string `xml:"href,attr"`
-	Rel  string `xml:"rel,attr"`
-	Type string `xml:"type,attr"`
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type atomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+// atomText is an Atom text construct; xhtml content is kept as markup
+type atomText struct {
+	Type     string `xml:"type,attr"`
+	Text     string `xml:",chardata"`
+	InnerXML string `xml:",innerxml"`
+}
+
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.InnerXML)
+	}
+	return strings.TrimSpace(t.Text)
 }
 
+// acceptHeader advertises the feed formats the reader understands
+const acceptHeader = "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.8"
+
 func (r *Reader) Read(url string) (*Channel, error) {
-	resp, err := r.client.Get(url)
+	return r.ReadContext(context.Background(), url)
+}
+
+// ReadContext is like Read, but the request is abandoned when ctx is done
+func (r *Reader) ReadContext(ctx context.Context, url string) (*Channel, error) {
+	result, err := r.Fetch(ctx, url)
+	return result.Channel, err
+}
+
+// HTTPError is returned when a feed is answered with an unexpected status
+type HTTPError struct {
+	StatusCode int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// FetchResult describes the outcome of fetching a feed
+type FetchResult struct {
+	Channel    *Channel // Nil when the fetch failed
+	StatusCode int      // HTTP status of the final response, 0 if there was none
+	MovedTo    string   // Final URL, if reached only through permanent redirects
+}
+
+// Fetch is like ReadContext, also reporting the HTTP status and whether
+// the feed has permanently moved. The result is filled in as far as the
+// fetch got, even when it fails.
+func (r *Reader) Fetch(ctx context.Context, url string) (FetchResult, error) {
+	var result FetchResult
+
+	ctx, redirects := withRedirectChain(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching feed: %w", err)
+		return result, fmt.Errorf("error fetching feed: %w", err)
+	}
+	req.Header.Set("Accept", acceptHeader)
+
+	// Send validators only when there's a cached channel to fall back on
+	var cached *CacheEntry
+	if r.cache != nil {
+		if entry, ok := r.cache.Get(url); ok && entry.Channel != nil {
+			cached = entry
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+	}
+
+	resp, err := r.clientFor(url).Do(req)
+	if err != nil {
+		return result, fmt.Errorf("error fetching feed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	result.StatusCode = resp.StatusCode
+	result.MovedTo = redirects.permanentTarget()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		cached.FetchedAt = time.Now()
+		r.cache.Put(url, cached)
+
+		channel := *cached.Channel
+		channel.FeedURL = url
+		dateItems(channel.Item) // Entries cached by older versions lack parsed dates
+		SortItems(channel.Item)
+		result.Channel = &channel
+		return result, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return result, &HTTPError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading feed: %w", err)
+		return result, fmt.Errorf("error reading feed: %w", err)
+	}
+
+	channel, err := r.parse(body, resp.Header.Get("Content-Type"), url)
+	if err != nil {
+		return result, err
+	}
+
+	if r.cache != nil {
+		// A failed cache write only costs us the next conditional request
+		r.cache.Put(url, &CacheEntry{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			FetchedAt:    time.Now(),
+			Channel:      channel,
+		})
 	}
 
-	// Detect feed type by checking the root element
-	bodyStr := string(body)
-	if strings.Contains(bodyStr, "<feed") || strings.Contains(bodyStr, "<feed>") {
-		return r.parseAtom(body, url)
+	result.Channel = channel
+	return result, nil
+}
+
+// parse dispatches the body to the parser for its detected format. XML that
+// isn't well-formed is retried once after sanitizing, with a non-strict
+// decoder; channels rescued that way are flagged as Recovered.
+func (r *Reader) parse(body []byte, contentType string, feedURL string) (*Channel, error) {
+	body = toUTF8(body, contentType)
+	format := detectFormat(contentType, body)
+
+	var channel *Channel
+	var err error
+	if format == formatJSON {
+		channel, err = r.parseJSONFeed(body, feedURL)
+	} else {
+		channel, err = r.parseXML(format, newXMLDecoder(body), feedURL)
+		if err != nil {
+			var lenientErr error
+			channel, lenientErr = r.parseXML(format, newLenientXMLDecoder(sanitizeXML(body)), feedURL)
+			if lenientErr != nil {
+				return nil, err
+			}
+			channel.Recovered = true
+			channel.RecoveryNote = recoveryNote(err)
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return r.parseRSS(body, url)
+	dateItems(channel.Item)
+	SortItems(channel.Item)
+
+	return channel, nil
 }
 
-func (r *Reader) parseRSS(body []byte, feedURL string) (*Channel, error) {
+func (r *Reader) parseXML(format feedFormat, d *xml.Decoder, feedURL string) (*Channel, error) {
+	switch format {
+	case formatAtom:
+		return r.parseAtom(d, feedURL)
+	case formatRDF:
+		return r.parseRDF(d, feedURL)
+	default:
+		return r.parseRSS(d, feedURL)
+	}
+}
+
+func (r *Reader) parseRSS(d *xml.Decoder, feedURL string) (*Channel, error) {
 	var feed rssFeed
-	err := xml.Unmarshal(body, &feed)
+	err := d.Decode(&feed)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing RSS feed: %w", err)
 	}
 
-	feed.Channel.FeedURL = feedURL
-	return &feed.Channel, nil
+	return convertRSS(feed.Channel, feed.Channel.Items, feedURL), nil
+}
+
+func (r *Reader) parseRDF(d *xml.Decoder, feedURL string) (*Channel, error) {
+	var feed rdfFeed
+	err := d.Decode(&feed)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing RDF feed: %w", err)
+	}
+
+	return convertRSS(feed.Channel, feed.Items, feedURL), nil
 }
 
-func (r *Reader) parseAtom(body []byte, feedURL string) (*Channel, error) {
+// convertRSS maps decoded RSS or RDF elements to a Channel
+func convertRSS(ch rssChannel, items []rssItem, feedURL string) *Channel {
+	channel := &Channel{
+		Title:       strings.TrimSpace(ch.Title),
+		Description: ch.Description,
+		Link:        strings.TrimSpace(ch.Link),
+		Language:    ch.Language,
+		Category:    ch.Category,
+		FeedURL:     feedURL,
+	}
+	ch.applyUpdateHints(channel)
+
+	channel.Item = make([]Item, len(items))
+	for i, entry := range items {
+		item := Item{
+			Title:       strings.TrimSpace(entry.Title),
+			Link:        strings.TrimSpace(entry.Link),
+			Description: entry.Description,
+			Content:     entry.Content,
+			GUID:        strings.TrimSpace(entry.GUID),
+			Author:      strings.TrimSpace(entry.Author),
+			Categories:  append(entry.Categories, entry.Subjects...),
+			Comments:    strings.TrimSpace(entry.Comments),
+			PubDate:     strings.TrimSpace(entry.PubDate),
+		}
+
+		// dc:creator is usually a plain name, prefer it over the RSS email form
+		if entry.Creator != "" {
+			item.Author = strings.TrimSpace(entry.Creator)
+		}
+		if item.PubDate == "" {
+			item.PubDate = strings.TrimSpace(entry.Date)
+		}
+		if item.Link == "" {
+			item.Link = strings.TrimSpace(entry.About)
+		}
+
+		for _, enc := range entry.Enclosures {
+			item.Enclosures = append(item.Enclosures, Enclosure{
+				URL:    enc.URL,
+				Length: parseLength(enc.Length),
+				Type:   enc.Type,
+			})
+		}
+
+		channel.Item[i] = item
+	}
+
+	return channel
+}
+
+func (r *Reader) parseAtom(d *xml.Decoder, feedURL string) (*Channel, error) {
 	var atom atomFeed
-	err := xml.Unmarshal(body, &atom)
+	err := d.Decode(&atom)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing Atom feed: %w", err)
 	}
@@ -101,36 +554,58 @@ func (r *Reader) parseAtom(body []byte, feedURL string) (*Channel, error) {
 	}
 
 	// Extract link from Atom feed (prefer alternate link, fallback to first link)
-	for _, link := range atom.Link {
-		if link.Rel == "alternate" || link.Rel == "" {
-			channel.Link = link.Href
-			break
-		}
-	}
-	if channel.Link == "" && len(atom.Link) > 0 {
-		channel.Link = atom.Link[0].Href
-	}
+	channel.Link = atomAlternateLink(atom.Link)
 
 	// Convert Atom entries to RSS items
 	channel.Item = make([]Item, len(atom.Entry))
 	for i, entry := range atom.Entry {
 		item := Item{
-			Title: entry.Title,
+			Title:       entry.Title,
+			Link:        atomAlternateLink(entry.Link),
+			Description: entry.Summary.String(),
+			Content:     entry.Content.String(),
+			GUID:        entry.ID,
 		}
 
-		// Extract link from entry (prefer alternate link, fallback to first link)
-		for _, link := range entry.Link {
-			if link.Rel == "alternate" || link.Rel == "" {
-				item.Link = link.Href
-				break
+		// Entries without a link are identified by their ID; it's only
+		// usable as the link when it's a web URL rather than a tag: or urn:
+		if item.Link == "" && isWebURL(entry.ID) {
+			item.Link = strings.TrimSpace(entry.ID)
+		}
+
+		// Entries inherit the feed author when they don't name one
+		authors := entry.Author
+		if len(authors) == 0 {
+			authors = atom.Author
+		}
+		if len(authors) > 0 {
+			item.Author = authors[0].Name
+			if item.Author == "" {
+				item.Author = authors[0].Email
 			}
 		}
-		if item.Link == "" && len(entry.Link) > 0 {
-			item.Link = entry.Link[0].Href
+
+		for _, category := range entry.Category {
+			if category.Label != "" {
+				item.Categories = append(item.Categories, category.Label)
+			} else if category.Term != "" {
+				item.Categories = append(item.Categories, category.Term)
+			}
 		}
-		// Fallback to entry ID if no link found
-		if item.Link == "" {
-			item.Link = entry.ID
+
+		for _, link := range entry.Link {
+			switch link.Rel {
+			case "enclosure":
+				item.Enclosures = append(item.Enclosures, Enclosure{
+					URL:    link.Href,
+					Length: parseLength(link.Length),
+					Type:   link.Type,
+				})
+			case "replies":
+				if item.Comments == "" && (link.Type == "" || link.Type == "text/html") {
+					item.Comments = link.Href
+				}
+			}
 		}
 
 		// Use published date if available, otherwise use updated date
@@ -145,3 +620,25 @@ func (r *Reader) parseAtom(body []byte, feedURL string) (*Channel, error) {
 
 	return channel, nil
 }
+
+// atomAlternateLink returns the alternate link, falling back to the first link
+func atomAlternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "alternate" || link.Rel == "" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+// parseLength parses an enclosure length, which publishers often leave empty
+func parseLength(s string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,163 @@
+package feed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	return data
+}
+
+func TestParseFixtures(t *testing.T) {
+	tests := []struct {
+		file        string
+		contentType string
+		format      feedFormat
+		title       string
+		items       int
+		firstTitle  string
+	}{
+		{"rss2.xml", "application/rss+xml", formatRSS, "Example Weblog", 2, "Second post"},
+		{"rss_mentions_feed.xml", "text/xml", formatRSS, "Markup Tips", 1, "How the <feed> element works"},
+		{"atom.xml", "application/atom+xml", formatAtom, "Example Atom", 1, "Atom entry"},
+		{"rdf.xml", "application/rdf+xml", formatRDF, "Agency Notices", 1, "Notice one"},
+		{"jsonfeed.json", "application/feed+json", formatJSON, "Example JSON Feed", 1, "JSON item"},
+		{"latin1.xml", "text/xml", formatRSS, "Café Crème", 1, "Déjà vu"},
+		{"latin1.xml", "text/xml; charset=utf-8", formatRSS, "Café Crème", 1, "Déjà vu"},
+		{"windows1252.xml", "", formatRSS, "“Smart” Quotes", 1, "Price: €100 – today"},
+		{"latin1_header_only.xml", "text/xml; charset=ISO-8859-1", formatRSS, "Naïve Header", 1, "Grüße"},
+	}
+
+	r := &Reader{}
+	for _, tt := range tests {
+		t.Run(tt.file+" "+tt.contentType, func(t *testing.T) {
+			body := readFixture(t, tt.file)
+
+			if got := detectFormat(tt.contentType, toUTF8(body, tt.contentType)); got != tt.format {
+				t.Errorf("detectFormat = %v, want %v", got, tt.format)
+			}
+
+			channel, err := r.parse(body, tt.contentType, "https://feeds.example/"+tt.file)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if channel.Title != tt.title {
+				t.Errorf("Title = %q, want %q", channel.Title, tt.title)
+			}
+			if len(channel.Item) != tt.items {
+				t.Fatalf("got %d items, want %d", len(channel.Item), tt.items)
+			}
+			if channel.Item[0].Title != tt.firstTitle {
+				t.Errorf("first item Title = %q, want %q", channel.Item[0].Title, tt.firstTitle)
+			}
+		})
+	}
+}
+
+func TestParseRSSItemModel(t *testing.T) {
+	channel, err := (&Reader{}).parse(readFixture(t, "rss2.xml"), "", "https://example.com/feed.xml")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if channel.Link != "https://example.com/" {
+		t.Errorf("channel Link = %q, atom:link must not replace the RSS link", channel.Link)
+	}
+
+	item := channel.Item[0]
+	if item.GUID != "example-2" {
+		t.Errorf("GUID = %q", item.GUID)
+	}
+	if item.Author != "Jane Doe" {
+		t.Errorf("Author = %q, want dc:creator", item.Author)
+	}
+	if item.Content != "<p>The full <em>content</em>.</p>" {
+		t.Errorf("Content = %q", item.Content)
+	}
+	if item.Description != "A short <b>summary</b>" {
+		t.Errorf("Description = %q", item.Description)
+	}
+	if len(item.Categories) != 2 || item.Categories[1] != "feeds" {
+		t.Errorf("Categories = %v", item.Categories)
+	}
+	if item.Comments != "https://example.com/second#comments" {
+		t.Errorf("Comments = %q", item.Comments)
+	}
+	if len(item.Enclosures) != 1 || item.Enclosures[0].Length != 1234 || item.Enclosures[0].Type != "audio/mpeg" {
+		t.Errorf("Enclosures = %+v", item.Enclosures)
+	}
+}
+
+func TestReadUsesContentTypeCharset(t *testing.T) {
+	body := readFixture(t, "latin1_header_only.xml")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml; charset=iso-8859-1")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	channel, err := NewReader().Read(server.URL)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if channel.Item[0].Title != "Grüße" {
+		t.Errorf("item Title = %q, want %q", channel.Item[0].Title, "Grüße")
+	}
+}
+
+// memoryCache is a minimal Cache for tests
+type memoryCache map[string]*CacheEntry
+
+func (c memoryCache) Get(feedURL string) (*CacheEntry, bool) {
+	entry, ok := c[feedURL]
+	return entry, ok
+}
+
+func (c memoryCache) Put(feedURL string, entry *CacheEntry) error {
+	c[feedURL] = entry
+	return nil
+}
+
+func TestReadConditionalGet(t *testing.T) {
+	body := readFixture(t, "rss2.xml")
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") == "Mon, 02 Jan 2006 15:04:05 GMT" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	reader := NewReader()
+	reader.SetCache(memoryCache{})
+
+	first, err := reader.Read(server.URL)
+	if err != nil {
+		t.Fatalf("first Read: %v", err)
+	}
+	second, err := reader.Read(server.URL)
+	if err != nil {
+		t.Fatalf("second Read: %v", err)
+	}
+
+	if requests != 2 {
+		t.Fatalf("server saw %d requests, want 2", requests)
+	}
+	if second.Title != first.Title || len(second.Item) != len(first.Item) {
+		t.Errorf("304 response did not reuse the cached channel: got %q with %d items", second.Title, len(second.Item))
+	}
+}
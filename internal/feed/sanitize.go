@@ -0,0 +1,159 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// entityRef matches a well-formed entity or character reference at the start
+// of the input, e.g. "&amp;", "&nbsp;", "&#8217;" or "&#x2019;"
+var entityRef = regexp.MustCompile(`^&(?:[A-Za-z][A-Za-z0-9]*|#[0-9]+|#[xX][0-9A-Fa-f]+);`)
+
+// voidElements are HTML elements that publishers embed unescaped and never
+// close. The stock xml.HTMLAutoClose list can't be used because it includes
+// "link", which is a regular element in RSS.
+var voidElements = []string{"br", "hr", "img", "input", "meta", "area", "base", "col", "param", "wbr"}
+
+// newLenientXMLDecoder returns a non-strict decoder that understands HTML
+// entities, for feeds that failed to parse strictly
+func newLenientXMLDecoder(body []byte) *xml.Decoder {
+	d := newXMLDecoder(body)
+	d.Strict = false
+	d.AutoClose = voidElements
+	d.Entity = xml.HTMLEntity
+	return d
+}
+
+// sanitizeXML repairs the well-formedness errors most often seen in real
+// feeds: invalid UTF-8 and control characters, unescaped ampersands, and
+// documents truncated before their closing tags
+func sanitizeXML(body []byte) []byte {
+	body = stripInvalidChars(body)
+	body = escapeBareAmpersands(body)
+	return closeOpenElements(body)
+}
+
+// stripInvalidChars removes bytes that can never appear in an XML 1.0 document
+func stripInvalidChars(body []byte) []byte {
+	out := make([]byte, 0, len(body))
+	for len(body) > 0 {
+		r, size := utf8.DecodeRune(body)
+		switch {
+		case r == utf8.RuneError && size <= 1:
+			// Invalid UTF-8 byte, drop it
+		case r < 0x20 && r != '\t' && r != '\n' && r != '\r':
+			// C0 control character
+		case r == 0xFFFE || r == 0xFFFF:
+		default:
+			out = append(out, body[:size]...)
+		}
+		body = body[size:]
+	}
+	return out
+}
+
+// escapeBareAmpersands escapes '&' characters that don't start an entity
+// reference. CDATA sections and comments are copied untouched.
+func escapeBareAmpersands(body []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(body))
+
+	for i := 0; i < len(body); {
+		switch {
+		case bytes.HasPrefix(body[i:], []byte("<![CDATA[")):
+			end := bytes.Index(body[i:], []byte("]]>"))
+			if end < 0 {
+				out.Write(body[i:])
+				return out.Bytes()
+			}
+			out.Write(body[i : i+end+3])
+			i += end + 3
+		case bytes.HasPrefix(body[i:], []byte("<!--")):
+			end := bytes.Index(body[i:], []byte("-->"))
+			if end < 0 {
+				out.Write(body[i:])
+				return out.Bytes()
+			}
+			out.Write(body[i : i+end+3])
+			i += end + 3
+		case body[i] == '&' && !entityRef.Match(body[i:]):
+			out.WriteString("&amp;")
+			i++
+		default:
+			out.WriteByte(body[i])
+			i++
+		}
+	}
+
+	return out.Bytes()
+}
+
+// closeOpenElements appends end tags for every element still open at the
+// end of the document. A document cut off mid-token is first trimmed back to
+// its last complete token.
+func closeOpenElements(body []byte) []byte {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+
+	var open []xml.Name
+	end := int64(0)
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			end = int64(len(body))
+			break
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) && syntaxErr.Msg == "unexpected EOF" {
+				break
+			}
+			// Not a truncation problem, leave it to the lenient decoder
+			return body
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			open = append(open, t.Name)
+		case xml.EndElement:
+			// Pop back to the matching element; stray end tags are ignored
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == t.Name {
+					open = open[:i]
+					break
+				}
+			}
+		}
+		end = d.InputOffset()
+	}
+
+	if len(open) == 0 && end == int64(len(body)) {
+		return body
+	}
+
+	var out bytes.Buffer
+	out.Write(body[:end])
+	for i := len(open) - 1; i >= 0; i-- {
+		out.WriteString("</")
+		if open[i].Space != "" {
+			out.WriteString(open[i].Space + ":")
+		}
+		out.WriteString(open[i].Local + ">")
+	}
+	return out.Bytes()
+}
+
+// recoveryNote summarises why a feed needed recovery, for display
+func recoveryNote(err error) string {
+	msg := err.Error()
+	if i := strings.LastIndex(msg, ": "); i >= 0 {
+		msg = msg[i+2:]
+	}
+	return msg
+}
@@ -0,0 +1,56 @@
+package feed
+
+import (
+	"testing"
+)
+
+func TestParseRecoversMalformedFeed(t *testing.T) {
+	channel, err := (&Reader{}).parse(readFixture(t, "malformed.xml"), "application/rss+xml", "https://broken.example.com/feed")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if !channel.Recovered || channel.RecoveryNote == "" {
+		t.Errorf("Recovered = %v, RecoveryNote = %q; want the channel flagged", channel.Recovered, channel.RecoveryNote)
+	}
+	if channel.Title != "Tom & Jerry\u00a0News" {
+		t.Errorf("Title = %q", channel.Title)
+	}
+	if len(channel.Item) != 2 {
+		t.Fatalf("got %d items, want 2", len(channel.Item))
+	}
+	if channel.Item[0].Title != "Café opens & closes" {
+		t.Errorf("item Title = %q", channel.Item[0].Title)
+	}
+	if channel.Item[0].Link != "https://broken.example.com/cafe?a=1&b=2" {
+		t.Errorf("item Link = %q", channel.Item[0].Link)
+	}
+	if channel.Item[1].Title != "Cut short" {
+		t.Errorf("truncated item Title = %q", channel.Item[1].Title)
+	}
+}
+
+func TestParseWellFormedFeedIsNotRecovered(t *testing.T) {
+	channel, err := (&Reader{}).parse(readFixture(t, "rss2.xml"), "", "https://example.com/feed.xml")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if channel.Recovered {
+		t.Errorf("well-formed feed flagged as recovered: %s", channel.RecoveryNote)
+	}
+}
+
+func TestEscapeBareAmpersands(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"a & b", "a &amp; b"},
+		{"&amp; &nbsp; &#8217; &#x2019;", "&amp; &nbsp; &#8217; &#x2019;"},
+		{"?a=1&b=2", "?a=1&amp;b=2"},
+		{"<![CDATA[a & b]]> & c", "<![CDATA[a & b]]> &amp; c"},
+		{"<!-- a & b --> &", "<!-- a & b --> &amp;"},
+	}
+	for _, tt := range tests {
+		if got := string(escapeBareAmpersands([]byte(tt.in))); got != tt.want {
+			t.Errorf("escapeBareAmpersands(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Default limits for a Scheduler
+const (
+	DefaultMaxConcurrent = 8
+	DefaultMaxPerHost    = 2
+)
+
+// Scheduler refreshes many feeds through one Reader, bounding the number of
+// requests in flight overall and to any single host
+type Scheduler struct {
+	reader        *Reader
+	maxConcurrent int
+	maxPerHost    int
+}
+
+// RefreshResult is the outcome of fetching one feed
+type RefreshResult struct {
+	URL string
+	FetchResult
+	Err error
+}
+
+// RefreshProgress is sent each time a feed of a refresh has been fetched
+type RefreshProgress struct {
+	Total  int
+	Done   int // Feeds fetched so far, including failures
+	Failed int
+	Result RefreshResult // The feed that just finished
+}
+
+// String formats the progress as "12/40 feeds, 2 failed"
+func (p RefreshProgress) String() string {
+	s := fmt.Sprintf("%d/%d feeds", p.Done, p.Total)
+	if p.Failed > 0 {
+		s += fmt.Sprintf(", %d failed", p.Failed)
+	}
+	return s
+}
+
+// NewScheduler creates a scheduler fetching through reader with the
+// default limits
+func NewScheduler(reader *Reader) *Scheduler {
+	return &Scheduler{
+		reader:        reader,
+		maxConcurrent: DefaultMaxConcurrent,
+		maxPerHost:    DefaultMaxPerHost,
+	}
+}
+
+// SetLimits changes how many feeds are fetched at once, overall and per
+// host. Values below 1 are treated as 1.
+func (s *Scheduler) SetLimits(maxConcurrent, maxPerHost int) {
+	s.maxConcurrent = max(maxConcurrent, 1)
+	s.maxPerHost = max(maxPerHost, 1)
+}
+
+// Refresh fetches the feeds in the background. A progress update is sent on
+// the returned channel as each feed finishes, and the channel is closed once
+// all are done. Cancelling ctx aborts requests in flight and skips the feeds
+// that haven't started; those are not reported.
+func (s *Scheduler) Refresh(ctx context.Context, feedURLs []string) <-chan RefreshProgress {
+	// Buffered for every feed, so workers never wait on a slow reader
+	updates := make(chan RefreshProgress, len(feedURLs))
+
+	global := make(chan struct{}, s.maxConcurrent)
+	hosts := make(map[string]chan struct{})
+	for _, feedURL := range feedURLs {
+		host := hostOf(feedURL)
+		if _, ok := hosts[host]; !ok {
+			hosts[host] = make(chan struct{}, s.maxPerHost)
+		}
+	}
+
+	var mu sync.Mutex
+	progress := RefreshProgress{Total: len(feedURLs)}
+
+	var wg sync.WaitGroup
+	for _, feedURL := range feedURLs {
+		wg.Add(1)
+		go func(feedURL string) {
+			defer wg.Done()
+
+			// Take the host slot first so waiting on a busy host doesn't
+			// hold one of the global slots
+			host := hosts[hostOf(feedURL)]
+			if !acquire(ctx, host) {
+				return
+			}
+			defer release(host)
+			if !acquire(ctx, global) {
+				return
+			}
+			defer release(global)
+
+			result, err := s.reader.Fetch(ctx, feedURL)
+			if ctx.Err() != nil {
+				return
+			}
+			if channel := result.Channel; channel != nil {
+				channel.FeedURL = feedURL
+				channel.FetchedAt = time.Now()
+				channel.Stale = false
+			}
+
+			mu.Lock()
+			progress.Done++
+			if err != nil {
+				progress.Failed++
+			}
+			progress.Result = RefreshResult{URL: feedURL, FetchResult: result, Err: err}
+			updates <- progress
+			mu.Unlock()
+		}(feedURL)
+	}
+
+	go func() {
+		wg.Wait()
+		close(updates)
+	}()
+
+	return updates
+}
+
+// hostOf returns the host a feed is fetched from, used to group requests
+func hostOf(feedURL string) string {
+	parsed, err := url.Parse(feedURL)
+	if err != nil || parsed.Host == "" {
+		return feedURL
+	}
+	return parsed.Host
+}
+
+// acquire takes a slot of sem, giving up when ctx is done
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func release(sem chan struct{}) {
+	<-sem
+}
@@ -0,0 +1,93 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedulerLimitsRequestsPerHost(t *testing.T) {
+	body := readFixture(t, "rss2.xml")
+
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		if r.URL.Path == "/broken" {
+			http.Error(w, "gone", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	var urls []string
+	for i := 0; i < 9; i++ {
+		urls = append(urls, fmt.Sprintf("%s/feed%d", server.URL, i))
+	}
+	urls = append(urls, server.URL+"/broken")
+
+	scheduler := NewScheduler(NewReader())
+	scheduler.SetLimits(8, 3)
+
+	var last RefreshProgress
+	seen := map[string]bool{}
+	for progress := range scheduler.Refresh(context.Background(), urls) {
+		if progress.Done != last.Done+1 {
+			t.Errorf("progress jumped from %d to %d", last.Done, progress.Done)
+		}
+		seen[progress.Result.URL] = true
+		if progress.Result.Err == nil && progress.Result.Channel.FeedURL != progress.Result.URL {
+			t.Errorf("channel FeedURL = %q, want %q", progress.Result.Channel.FeedURL, progress.Result.URL)
+		}
+		last = progress
+	}
+
+	if len(seen) != len(urls) {
+		t.Errorf("got results for %d feeds, want %d", len(seen), len(urls))
+	}
+	if got := last.String(); got != "10/10 feeds, 1 failed" {
+		t.Errorf("final progress = %q", got)
+	}
+	if peak > 3 {
+		t.Errorf("%d requests in flight to one host, limit is 3", peak)
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := NewScheduler(NewReader()).Refresh(ctx, []string{server.URL + "/a", server.URL + "/b", server.URL + "/c"})
+	cancel()
+
+	select {
+	case progress, ok := <-updates:
+		if ok {
+			t.Errorf("got progress %v after cancel", progress)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("refresh did not stop after cancel")
+	}
+}
@@ -0,0 +1,104 @@
+package feed
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// syndicationPeriods maps sy:updatePeriod values to their length
+var syndicationPeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// maxHintInterval caps how long the publisher's hints can put off polling,
+// so a huge ttl or a bogus update period doesn't stop a feed for good
+const maxHintInterval = 24 * time.Hour
+
+// weekdays maps RSS <skipDays> names to weekdays
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// applyUpdateHints sets the channel's polling hints from the RSS <ttl>,
+// <skipHours> and <skipDays> elements and the syndication module. Values
+// that don't parse are ignored.
+func (c rssChannel) applyUpdateHints(channel *Channel) {
+	if minutes, err := strconv.Atoi(strings.TrimSpace(c.TTL)); err == nil && minutes > 0 {
+		channel.TTL = time.Duration(minutes) * time.Minute
+	}
+
+	for _, hour := range c.SkipHours {
+		h, err := strconv.Atoi(strings.TrimSpace(hour))
+		if err != nil || h < 0 || h > 24 {
+			continue
+		}
+		// Some feeds count hours 1-24
+		channel.SkipHours = append(channel.SkipHours, h%24)
+	}
+
+	for _, day := range c.SkipDays {
+		if weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(day))]; ok {
+			channel.SkipDays = append(channel.SkipDays, weekday)
+		}
+	}
+
+	// Both elements default when only one is given: daily, once
+	period := strings.ToLower(strings.TrimSpace(c.UpdatePeriod))
+	frequency := strings.TrimSpace(c.UpdateFrequency)
+	if period == "" && frequency == "" {
+		return
+	}
+	length, ok := syndicationPeriods[period]
+	if period == "" {
+		length, ok = syndicationPeriods["daily"], true
+	}
+	times := 1
+	if n, err := strconv.Atoi(frequency); err == nil && n > 0 {
+		times = n
+	}
+	if ok {
+		channel.UpdateInterval = length / time.Duration(times)
+	}
+}
+
+// NextRefresh returns when the channel should be polled next, given when it
+// was last polled and the interval the user asked for. The publisher's ttl
+// and syndication period can only lengthen the interval, up to a day, and
+// the result is moved past any skipped hours and days (which are in GMT).
+func (c *Channel) NextRefresh(last time.Time, interval time.Duration) time.Time {
+	hint := min(max(c.TTL, c.UpdateInterval), maxHintInterval)
+	next := last.Add(max(interval, hint))
+
+	// Step an hour at a time; give up after a week in case every hour is skipped
+	for i := 0; i < 7*24 && c.skipped(next); i++ {
+		next = next.Truncate(time.Hour).Add(time.Hour)
+	}
+	return next
+}
+
+// skipped reports whether the publisher asked not to be polled at t
+func (c *Channel) skipped(t time.Time) bool {
+	t = t.UTC()
+	for _, hour := range c.SkipHours {
+		if t.Hour() == hour {
+			return true
+		}
+	}
+	for _, day := range c.SkipDays {
+		if t.Weekday() == day {
+			return true
+		}
+	}
+	return false
+}
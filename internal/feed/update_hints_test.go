@@ -0,0 +1,69 @@
+package feed
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseUpdateHints(t *testing.T) {
+	const doc = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:sy="http://purl.org/rss/1.0/modules/syndication/">
+  <channel>
+    <title>Hints</title>
+    <ttl>90</ttl>
+    <skipHours><hour>0</hour><hour>24</hour><hour>3</hour><hour>x</hour></skipHours>
+    <skipDays><day>Saturday</day><day>sunday</day></skipDays>
+    <sy:updatePeriod>daily</sy:updatePeriod>
+    <sy:updateFrequency>4</sy:updateFrequency>
+  </channel>
+</rss>`
+
+	channel, err := (&Reader{}).parse([]byte(doc), "", "https://example.com/feed.xml")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if channel.TTL != 90*time.Minute {
+		t.Errorf("TTL = %v", channel.TTL)
+	}
+	if !reflect.DeepEqual(channel.SkipHours, []int{0, 0, 3}) {
+		t.Errorf("SkipHours = %v", channel.SkipHours)
+	}
+	if !reflect.DeepEqual(channel.SkipDays, []time.Weekday{time.Saturday, time.Sunday}) {
+		t.Errorf("SkipDays = %v", channel.SkipDays)
+	}
+	if channel.UpdateInterval != 6*time.Hour {
+		t.Errorf("UpdateInterval = %v", channel.UpdateInterval)
+	}
+}
+
+func TestNextRefresh(t *testing.T) {
+	// A Friday
+	last := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		channel  Channel
+		interval time.Duration
+		want     time.Time
+	}{
+		{"no hints", Channel{}, time.Hour, last.Add(time.Hour)},
+		{"ttl longer than interval", Channel{TTL: 3 * time.Hour}, time.Hour, last.Add(3 * time.Hour)},
+		{"ttl shorter than interval", Channel{TTL: 5 * time.Minute}, time.Hour, last.Add(time.Hour)},
+		{"update period", Channel{UpdateInterval: 24 * time.Hour}, time.Hour, last.Add(24 * time.Hour)},
+		{"huge ttl", Channel{TTL: 1000000 * time.Minute}, time.Hour, last.Add(24 * time.Hour)},
+		{"yearly update period", Channel{UpdateInterval: 365 * 24 * time.Hour}, time.Hour, last.Add(24 * time.Hour)},
+		{"interval longer than the cap", Channel{TTL: 365 * 24 * time.Hour}, 48 * time.Hour, last.Add(48 * time.Hour)},
+		{"skipped hours", Channel{SkipHours: []int{10, 11}}, 30 * time.Minute, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"skipped days", Channel{SkipDays: []time.Weekday{time.Saturday, time.Sunday}}, 20 * time.Hour, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.channel.NextRefresh(last, tt.interval); !got.Equal(tt.want) {
+				t.Errorf("NextRefresh = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package server
+
+import (
+	"bloom/internal/feed"
+	"bloom/internal/storage"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Handler returns the API:
+//
+//	GET    /api/feeds                     List the feeds
+//	POST   /api/feeds                     Subscribe, body {"url", "category", "tags"}
+//	DELETE /api/feeds?url=URL             Unsubscribe
+//	GET    /api/items                     List items; filters feed, category, tag, unread, limit
+//	GET    /api/article?url=URL[&feed=]   Extract an article
+//	POST   /api/read                      Mark items read, body {"links": [...], "ids": [...]}
+//	POST   /api/unread                    Mark items unread, body {"links": [...], "ids": [...]}
+//	POST   /api/refresh[?feed=URL]        Refresh all feeds, or one, in the background
+//
+// POST requests must be sent as application/json, and every request must be
+// addressed to the API's own host, so web pages can't use it.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/feeds", s.listFeeds)
+	mux.HandleFunc("POST /api/feeds", s.addFeed)
+	mux.HandleFunc("DELETE /api/feeds", s.removeFeed)
+	mux.HandleFunc("GET /api/items", s.listItems)
+	mux.HandleFunc("GET /api/article", s.getArticle)
+	mux.HandleFunc("POST /api/read", s.markRead(true))
+	mux.HandleFunc("POST /api/unread", s.markRead(false))
+	mux.HandleFunc("POST /api/refresh", s.refresh)
+	return s.guard(mux)
+}
+
+// loopbackHosts are the host names the API may always be addressed by
+var loopbackHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// guard rejects the requests a web page the user visits could make: ones
+// addressed to another host name, as after DNS rebinding, and POSTs that
+// aren't JSON, which pages can send without a CORS preflight
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.allowedHosts) > 0 {
+			host, _, err := net.SplitHostPort(r.Host)
+			if err != nil {
+				host = strings.Trim(r.Host, "[]")
+			}
+			if !slices.ContainsFunc(s.allowedHosts, func(h string) bool { return strings.EqualFold(h, host) }) {
+				writeError(w, http.StatusForbidden, fmt.Errorf("requests to host %q aren't allowed", r.Host))
+				return
+			}
+		}
+		if r.Method == http.MethodPost {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("expected Content-Type application/json"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// FeedJSON is a feed as returned by the API
+type FeedJSON struct {
+	URL       string      `json:"url"`
+	Title     string      `json:"title"`
+	Link      string      `json:"link,omitempty"`
+	Category  string      `json:"category"`
+	Tags      []string    `json:"tags"`
+	Paused    bool        `json:"paused"`
+	Disabled  bool        `json:"disabled"`
+	Items     int         `json:"items"`
+	Unread    int         `json:"unread"`
+	FetchedAt *time.Time  `json:"fetched_at,omitempty"`
+	Stale     bool        `json:"stale"`
+	Health    *HealthJSON `json:"health,omitempty"`
+}
+
+// HealthJSON is how fetching a feed has been going
+type HealthJSON struct {
+	LastAttempt         time.Time `json:"last_attempt"`
+	LastSuccess         time.Time `json:"last_success"`
+	StatusCode          int       `json:"status_code"`
+	LastError           string    `json:"last_error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+}
+
+// ItemJSON is an item as returned by the API
+type ItemJSON struct {
+	ID          string     `json:"id"`
+	Feed        string     `json:"feed"`
+	FeedURL     string     `json:"feed_url"`
+	Title       string     `json:"title"`
+	Link        string     `json:"link"`
+	GUID        string     `json:"guid,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Categories  []string   `json:"categories,omitempty"`
+	Published   *time.Time `json:"published,omitempty"`
+	Read        bool       `json:"read"`
+}
+
+func (s *Server) listFeeds(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	feeds := []FeedJSON{}
+	for _, feedConfig := range s.config.Feeds {
+		feeds = append(feeds, s.feedJSON(feedConfig))
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, feeds)
+}
+
+// feedJSON describes a configured feed. s.mu must be held.
+func (s *Server) feedJSON(feedConfig storage.FeedConfig) FeedJSON {
+	entry := FeedJSON{
+		URL:      feedConfig.URL,
+		Title:    feedConfig.Title,
+		Paused:   feedConfig.Paused,
+		Category: feedConfig.Category,
+		Tags:     feedConfig.Tags,
+		Disabled: feedConfig.Disabled,
+	}
+	if entry.Tags == nil {
+		entry.Tags = []string{}
+	}
+	if health := s.state.Health(feedConfig.URL); health != nil {
+		entry.Health = &HealthJSON{
+			LastAttempt:         health.LastAttempt,
+			LastSuccess:         health.LastSuccess,
+			StatusCode:          health.StatusCode,
+			LastError:           health.LastError,
+			ConsecutiveFailures: health.ConsecutiveFailures,
+		}
+	}
+	if channel := s.channels[feedConfig.URL]; channel != nil {
+		entry.Title = feedConfig.DisplayTitle(channel.Title)
+		entry.Link = channel.Link
+		entry.Items = len(channel.Item)
+		entry.Stale = channel.Stale
+		if !channel.FetchedAt.IsZero() {
+			fetchedAt := channel.FetchedAt
+			entry.FetchedAt = &fetchedAt
+		}
+		for _, item := range channel.Item {
+			if !s.state.IsItemRead(item) {
+				entry.Unread++
+			}
+		}
+	}
+	return entry
+}
+
+// addFeedRequest is the body of POST /api/feeds
+type addFeedRequest struct {
+	URL      string   `json:"url"`
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+}
+
+func (s *Server) addFeed(w http.ResponseWriter, r *http.Request) {
+	var req addFeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		return
+	}
+	if strings.TrimSpace(req.URL) == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("url is required"))
+		return
+	}
+
+	// Resolve a site URL to its feed, with the credentials of the site's
+	// configured feeds
+	feedURL := storage.NormalizeFeedURL(req.URL)
+	s.mu.Lock()
+	client, err := s.config.DiscoveryClient(s.clients, feedURL)
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("invalid HTTP settings: %v", err))
+		return
+	}
+	found, err := s.reader.DiscoverWith(client, feedURL)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if len(found) > 1 {
+		writeJSON(w, http.StatusMultipleChoices, map[string]any{
+			"error":      "the site offers several feeds, add one of them",
+			"candidates": found,
+		})
+		return
+	}
+
+	s.mu.Lock()
+	added := s.config.MergeFeeds([]storage.FeedConfig{{
+		URL:      found[0].URL,
+		Category: req.Category,
+		Tags:     req.Tags,
+	}})
+	if len(added) == 0 {
+		s.mu.Unlock()
+		writeError(w, http.StatusConflict, fmt.Errorf("already subscribed to %s", found[0].URL))
+		return
+	}
+	err = storage.SaveConfig(s.config)
+	if err == nil {
+		s.configureClients()
+	}
+	entry := s.feedJSON(added[0])
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Queued if a refresh is in progress
+	go s.Refresh(s.ctx, []string{added[0].URL})
+	writeJSON(w, http.StatusCreated, entry)
+}
+
+func (s *Server) removeFeed(w http.ResponseWriter, r *http.Request) {
+	feedURL := storage.NormalizeFeedURL(r.URL.Query().Get("url"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.config.RemoveFeed(feedURL) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("not subscribed to %s", feedURL))
+		return
+	}
+	delete(s.channels, feedURL)
+	delete(s.lastRefresh, feedURL)
+	s.configureClients()
+	if err := storage.SaveConfig(s.config); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listItems(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	feedURL := query.Get("feed")
+	if feedURL != "" {
+		feedURL = storage.NormalizeFeedURL(feedURL)
+	}
+	category := query.Get("category")
+	tag := query.Get("tag")
+	unread, _ := strconv.ParseBool(query.Get("unread"))
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit < 0 {
+		limit = 0
+	}
+
+	s.mu.Lock()
+	items := []ItemJSON{}
+	for _, feedConfig := range s.config.Feeds {
+		channel := s.channels[feedConfig.URL]
+		switch {
+		case channel == nil:
+			continue
+		case feedURL != "" && feedConfig.URL != feedURL:
+			continue
+		case category != "" && feedConfig.Category != category:
+			continue
+		case tag != "" && !slices.Contains(feedConfig.Tags, tag):
+			continue
+		}
+		for _, item := range channel.Item {
+			read := s.state.IsItemRead(item)
+			if unread && read {
+				continue
+			}
+			items = append(items, itemJSON(feedConfig, channel, item, read))
+		}
+	}
+	s.mu.Unlock()
+
+	// Newest first across feeds
+	slices.SortStableFunc(items, func(a, b ItemJSON) int {
+		switch {
+		case a.Published == nil && b.Published == nil:
+			return 0
+		case a.Published == nil:
+			return 1
+		case b.Published == nil:
+			return -1
+		}
+		return b.Published.Compare(*a.Published)
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	writeJSON(w, http.StatusOK, items)
+}
+
+// itemJSON describes an item of a configured feed's channel
+func itemJSON(feedConfig storage.FeedConfig, channel *feed.Channel, item feed.Item, read bool) ItemJSON {
+	entry := ItemJSON{
+		ID:          item.ID(),
+		Feed:        feedConfig.DisplayTitle(channel.Title),
+		FeedURL:     channel.FeedURL,
+		Title:       item.Title,
+		Link:        item.Link,
+		GUID:        item.GUID,
+		Author:      item.Author,
+		Description: item.Description,
+		Categories:  item.Categories,
+		Read:        read,
+	}
+	if !item.Published.IsZero() {
+		published := item.Published
+		entry.Published = &published
+	}
+	return entry
+}
+
+func (s *Server) getArticle(w http.ResponseWriter, r *http.Request) {
+	articleURL := r.URL.Query().Get("url")
+	if articleURL == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("url is required"))
+		return
+	}
+
+	article, err := s.fetcher.ExtractFromFeed(r.URL.Query().Get("feed"), articleURL)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, article)
+}
+
+// markRequest is the body of POST /api/read and /api/unread. Items are
+// given by link or by ID.
+type markRequest struct {
+	Links []string `json:"links"`
+	IDs   []string `json:"ids"`
+}
+
+// markRead returns a handler marking the items in the request read or unread
+func (s *Server) markRead(read bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req markRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		channels := make([]*feed.Channel, 0, len(s.channels))
+		for _, channel := range s.channels {
+			channels = append(channels, channel)
+		}
+		s.state.MarkItems(channels, append(req.Links, req.IDs...), read)
+		if err := storage.SaveState(s.state); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) refresh(w http.ResponseWriter, r *http.Request) {
+	var urls []string
+	if feedURL := r.URL.Query().Get("feed"); feedURL != "" {
+		feedURL = storage.NormalizeFeedURL(feedURL)
+		s.mu.Lock()
+		found := slices.ContainsFunc(s.config.Feeds, func(f storage.FeedConfig) bool { return f.URL == feedURL })
+		s.mu.Unlock()
+		if !found {
+			writeError(w, http.StatusNotFound, fmt.Errorf("not subscribed to %s", feedURL))
+			return
+		}
+		urls = []string{feedURL}
+	} else {
+		s.mu.Lock()
+		for _, feedConfig := range s.config.Feeds {
+			if feedConfig.Enabled() {
+				urls = append(urls, feedConfig.URL)
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	// Queued if a refresh is in progress
+	go s.Refresh(s.ctx, urls)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// writeJSON writes v as the response body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as {"error": "..."}
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,316 @@
+// Package server exposes bloom's subscriptions and read state over a local
+// HTTP/JSON API, refreshing the feeds in the background
+package server
+
+import (
+	"bloom/internal/feed"
+	"bloom/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// checkInterval is how often the background refresh looks for due feeds
+const checkInterval = time.Minute
+
+// Server holds the config, state and fetched feeds shared by the API
+// handlers and the background refresh
+type Server struct {
+	clients   *feed.ClientFactory
+	reader    *feed.Reader
+	fetcher   *feed.ArticleFetcher
+	cache     *storage.FeedCache
+	scheduler *feed.Scheduler
+
+	mu          sync.Mutex
+	config      *storage.Config
+	state       *storage.AppState
+	channels    map[string]*feed.Channel // Keyed by feed URL
+	lastRefresh map[string]time.Time
+	refreshing  bool
+
+	// pending are the feeds to refresh once the refresh in progress is
+	// done, and staleClients says the config changed during it so the HTTP
+	// clients are rebuilt after it
+	pending      []string
+	staleClients bool
+
+	// allowedHosts are the host names requests may be addressed to,
+	// against DNS rebinding; empty allows any, for unix sockets
+	allowedHosts []string
+
+	// ctx is Serve's, cancelling the refreshes the API starts on shutdown
+	ctx context.Context
+}
+
+// New creates a server for config and state. Feeds in the offline cache are
+// served until they're refreshed.
+func New(config *storage.Config, state *storage.AppState) (*Server, error) {
+	s := &Server{
+		clients:     feed.NewClientFactory(),
+		reader:      feed.NewReader(),
+		fetcher:     feed.NewArticleFetcher(),
+		cache:       storage.NewFeedCache(),
+		config:      config,
+		state:       state,
+		channels:    make(map[string]*feed.Channel),
+		lastRefresh: make(map[string]time.Time),
+
+		allowedHosts: loopbackHosts,
+		ctx:          context.Background(),
+	}
+	s.reader.SetCache(s.cache)
+	s.scheduler = feed.NewScheduler(s.reader)
+	if err := config.ConfigureClients(s.clients, s.reader, s.fetcher); err != nil {
+		return nil, fmt.Errorf("invalid HTTP settings: %v", err)
+	}
+
+	for _, feedConfig := range config.Feeds {
+		if entry, ok := s.cache.Get(feedConfig.URL); ok && entry.Channel != nil {
+			channel := entry.Channel
+			channel.FeedURL = feedConfig.URL
+			channel.Stale = true
+			s.channels[feedConfig.URL] = channel
+		}
+	}
+
+	if storage.CompactState(config, state, s.cache, time.Now()) > 0 {
+		if err := storage.SaveState(state); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Serve answers API requests on l and refreshes due feeds in the background
+// until ctx is cancelled
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	if l.Addr().Network() == "unix" {
+		// Web pages can't reach a unix socket
+		s.allowedHosts = nil
+	} else if host, _, err := net.SplitHostPort(l.Addr().String()); err == nil && !net.ParseIP(host).IsUnspecified() {
+		s.allowedHosts = append(slices.Clone(loopbackHosts), host)
+	}
+	s.ctx = ctx
+	httpServer := &http.Server{Handler: s.Handler()}
+
+	go s.refreshLoop(ctx)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// refreshLoop refreshes the feeds that are due now and every checkInterval
+func (s *Server) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		s.Refresh(ctx, s.dueFeeds(time.Now()))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dueFeeds returns the enabled feeds whose refresh interval has passed
+func (s *Server) dueFeeds(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []string
+	for _, feedConfig := range s.config.Feeds {
+		interval := s.config.RefreshInterval(feedConfig)
+		if !feedConfig.Enabled() {
+			continue
+		}
+		last, fetched := s.lastRefresh[feedConfig.URL]
+		if fetched && interval == 0 {
+			continue
+		}
+		next := last.Add(interval)
+		if channel := s.channels[feedConfig.URL]; channel != nil && fetched {
+			// The publisher may ask us to poll less often
+			next = channel.NextRefresh(last, interval)
+		}
+		if !now.Before(next) {
+			due = append(due, feedConfig.URL)
+		}
+	}
+	return due
+}
+
+// Refresh fetches the feeds and records the outcome in the state, saving
+// the state and, if feeds moved or are gone, the config. Refreshes don't
+// overlap; if one is in progress the feeds are queued to be fetched after
+// it, and the call returns false.
+func (s *Server) Refresh(ctx context.Context, urls []string) bool {
+	s.mu.Lock()
+	if s.refreshing {
+		for _, url := range urls {
+			if !slices.Contains(s.pending, url) {
+				s.pending = append(s.pending, url)
+			}
+		}
+		s.mu.Unlock()
+		return false
+	}
+	s.refreshing = true
+	s.mu.Unlock()
+
+	for {
+		s.fetch(ctx, urls)
+
+		s.mu.Lock()
+		if s.staleClients {
+			s.staleClients = false
+			s.rebuildClients()
+		}
+		urls, s.pending = s.pending, nil
+		if len(urls) == 0 || ctx.Err() != nil {
+			s.refreshing = false
+			s.mu.Unlock()
+			return true
+		}
+		s.mu.Unlock()
+	}
+}
+
+// fetch refreshes the feeds for Refresh
+func (s *Server) fetch(ctx context.Context, urls []string) {
+	if len(urls) == 0 {
+		return
+	}
+	s.mu.Lock()
+	now := time.Now()
+	for _, url := range urls {
+		s.lastRefresh[url] = now
+	}
+	s.mu.Unlock()
+
+	configChanged := false
+	for progress := range s.scheduler.Refresh(ctx, urls) {
+		s.mu.Lock()
+		configChanged = s.applyFetch(progress.Result) || configChanged
+		s.mu.Unlock()
+	}
+
+	// Save copies, so the API isn't held up by the disk
+	s.mu.Lock()
+	s.state.LastSync = time.Now()
+	state := s.state.Clone()
+	var config *storage.Config
+	if configChanged {
+		config = s.config.Clone()
+	}
+	s.mu.Unlock()
+
+	if err := storage.SaveState(state); err != nil {
+		log.Printf("bloom serve: %v", err)
+	}
+	if config != nil {
+		if err := storage.SaveConfig(config); err != nil {
+			log.Printf("bloom serve: %v", err)
+		}
+	}
+}
+
+// configureClients rebuilds the HTTP clients from the config, or, while a
+// refresh is fetching with them, once it's done. s.mu must be held.
+func (s *Server) configureClients() {
+	if s.refreshing {
+		s.staleClients = true
+		return
+	}
+	s.rebuildClients()
+}
+
+// rebuildClients points the reader and fetcher at clients built from the
+// config. s.mu must be held.
+func (s *Server) rebuildClients() {
+	if err := s.config.ConfigureClients(s.clients, s.reader, s.fetcher); err != nil {
+		log.Printf("bloom serve: invalid HTTP settings: %v", err)
+	}
+}
+
+// applyFetch merges the outcome of fetching a feed, reporting whether the
+// config changed. s.mu must be held.
+func (s *Server) applyFetch(result feed.RefreshResult) bool {
+	if result.Err != nil {
+		if cached := s.channels[result.URL]; cached != nil {
+			cached.Stale = true
+		}
+	} else if result.Channel != nil {
+		s.mergeFeed(result.Channel)
+	}
+
+	outcome := storage.UpdateAfterFetch(s.config, s.state, result.URL, time.Now(), result.FetchResult, result.Err)
+	if outcome.Disabled {
+		log.Printf("bloom serve: %s is gone, disabled", result.URL)
+	}
+	if outcome.MovedTo != "" {
+		log.Printf("bloom serve: %s moved to %s", result.URL, outcome.MovedTo)
+		s.moveFeed(result.URL, outcome.MovedTo)
+	}
+	return outcome.ConfigChanged()
+}
+
+// mergeFeed stores a fetched channel, keeping the items that dropped out of
+// the feed since it was last fetched. s.mu must be held.
+func (s *Server) mergeFeed(channel *feed.Channel) {
+	feedConfig, _ := s.config.FindFeed(channel.FeedURL)
+	existing := s.channels[channel.FeedURL]
+	s.channels[channel.FeedURL] = channel
+	if existing == nil {
+		channel.Item = feedConfig.LimitItems(channel.Item)
+		return
+	}
+
+	fetched := make(map[string]bool, len(channel.Item))
+	for _, item := range channel.Item {
+		fetched[item.ID()] = true
+	}
+	for _, item := range existing.Item {
+		if !fetched[item.ID()] {
+			channel.Item = append(channel.Item, item)
+		}
+	}
+	feed.SortItems(channel.Item)
+	channel.Item = feedConfig.LimitItems(channel.Item)
+}
+
+// moveFeed points a feed's channel, cache entry and client at its new URL.
+// s.mu must be held.
+func (s *Server) moveFeed(oldURL, newURL string) {
+	if channel := s.channels[oldURL]; channel != nil {
+		delete(s.channels, oldURL)
+		if s.channels[newURL] == nil {
+			channel.FeedURL = newURL
+			s.channels[newURL] = channel
+		}
+	}
+	s.lastRefresh[newURL] = s.lastRefresh[oldURL]
+	delete(s.lastRefresh, oldURL)
+
+	if err := s.cache.Move(oldURL, newURL); err != nil {
+		log.Printf("bloom serve: %v", err)
+	}
+	// Per-feed clients are keyed by URL
+	s.configureClients()
+}
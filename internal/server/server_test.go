@@ -0,0 +1,199 @@
+package server
+
+import (
+	"bloom/internal/storage"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Test Feed</title>
+<item><title>First</title><link>%[1]s/1</link><pubDate>Mon, 02 Jan 2006 15:04:05 GMT</pubDate></item>
+<item><title>Second</title><link>%[1]s/2</link><pubDate>Tue, 03 Jan 2006 15:04:05 GMT</pubDate></item>
+</channel></rss>`
+
+const testArticle = `<html><head><title>First</title></head><body><article>
+<h1>First</h1><p>The first article has enough text in it to be recognised as the main content of the page.</p>
+</article></body></html>`
+
+// newTestAPI starts a feed site and an API server subscribed to its feed
+func newTestAPI(t *testing.T) (srv *Server, api *httptest.Server, site *httptest.Server) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("BLOOM_CONFIG", filepath.Join(dir, "config.json"))
+	t.Setenv("BLOOM_STATE", filepath.Join(dir, "state.json"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+
+	site = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/feed.xml" {
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte(strings.ReplaceAll(testFeed, "%[1]s", "http://"+r.Host)))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(testArticle))
+	}))
+	t.Cleanup(site.Close)
+
+	config := storage.DefaultConfig()
+	config.Feeds = []storage.FeedConfig{{URL: site.URL + "/feed.xml", Category: "Test"}}
+	srv, err := New(config, storage.NewAppState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !srv.Refresh(context.Background(), srv.dueFeeds(time.Now())) {
+		t.Fatal("refresh didn't run")
+	}
+
+	api = httptest.NewServer(srv.Handler())
+	t.Cleanup(api.Close)
+	return srv, api, site
+}
+
+// call makes an API request and decodes the response into out, if given
+func call(t *testing.T, method, u, body string, wantStatus int, out any) {
+	t.Helper()
+	req, err := http.NewRequest(method, u, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("%s %s: status %d, want %d", method, u, resp.StatusCode, wantStatus)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAPI(t *testing.T) {
+	srv, api, site := newTestAPI(t)
+	feedURL := site.URL + "/feed.xml"
+
+	var feeds []FeedJSON
+	call(t, "GET", api.URL+"/api/feeds", "", http.StatusOK, &feeds)
+	if len(feeds) != 1 || feeds[0].Title != "Test Feed" || feeds[0].Unread != 2 || feeds[0].Health == nil {
+		t.Fatalf("unexpected feeds: %+v", feeds)
+	}
+
+	var items []ItemJSON
+	call(t, "GET", api.URL+"/api/items?category=Test", "", http.StatusOK, &items)
+	if len(items) != 2 || items[0].Title != "Second" {
+		t.Fatalf("expected both items, newest first: %+v", items)
+	}
+
+	call(t, "POST", api.URL+"/api/read", `{"links": ["`+site.URL+`/2"]}`, http.StatusNoContent, nil)
+	call(t, "GET", api.URL+"/api/items?unread=true", "", http.StatusOK, &items)
+	if len(items) != 1 || items[0].Title != "First" {
+		t.Fatalf("expected the unread item: %+v", items)
+	}
+	call(t, "POST", api.URL+"/api/unread", `{"links": ["`+site.URL+`/2"]}`, http.StatusNoContent, nil)
+	call(t, "GET", api.URL+"/api/items?unread=true&limit=1", "", http.StatusOK, &items)
+	if len(items) != 1 || items[0].Title != "Second" {
+		t.Fatalf("expected the newest unread item: %+v", items)
+	}
+
+	var article struct{ Title, Content string }
+	call(t, "GET", api.URL+"/api/article?url="+url.QueryEscape(site.URL+"/1"), "", http.StatusOK, &article)
+	if !strings.Contains(article.Content, "first article") {
+		t.Fatalf("unexpected article: %+v", article)
+	}
+
+	call(t, "POST", api.URL+"/api/feeds", `{"url": "`+feedURL+`"}`, http.StatusConflict, nil)
+	call(t, "DELETE", api.URL+"/api/feeds?url="+url.QueryEscape(feedURL), "", http.StatusNoContent, nil)
+	call(t, "GET", api.URL+"/api/feeds", "", http.StatusOK, &feeds)
+	if len(feeds) != 0 {
+		t.Fatalf("expected no feeds after removal: %+v", feeds)
+	}
+	call(t, "DELETE", api.URL+"/api/feeds?url="+url.QueryEscape(feedURL), "", http.StatusNotFound, nil)
+
+	var added FeedJSON
+	call(t, "POST", api.URL+"/api/feeds", `{"url": "`+feedURL+`", "tags": ["x"]}`, http.StatusCreated, &added)
+	if added.URL != feedURL || len(added.Tags) != 1 {
+		t.Fatalf("unexpected added feed: %+v", added)
+	}
+
+	// The new feed is fetched in the background
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		call(t, "GET", api.URL+"/api/feeds", "", http.StatusOK, &feeds)
+		srv.mu.Lock()
+		done := !srv.refreshing
+		srv.mu.Unlock()
+		if done && len(feeds) == 1 && feeds[0].Items == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("added feed wasn't fetched: %+v", feeds)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAPIRejectsRequestsWebPagesCanMake(t *testing.T) {
+	_, api, _ := newTestAPI(t)
+
+	// A form or no-cors fetch can only send simple content types
+	resp, err := http.Post(api.URL+"/api/refresh", "text/plain", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain POST: status %d", resp.StatusCode)
+	}
+
+	// After DNS rebinding, requests carry the attacker's host name
+	req, err := http.NewRequest("GET", api.URL+"/api/feeds", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Host = "evil.example:7070"
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("request to another host: status %d", resp.StatusCode)
+	}
+}
+
+func TestRefreshQueuesWhileRunning(t *testing.T) {
+	srv, _, site := newTestAPI(t)
+	feedURL := site.URL + "/feed.xml"
+
+	srv.mu.Lock()
+	srv.refreshing = true
+	srv.mu.Unlock()
+	if srv.Refresh(context.Background(), []string{feedURL}) {
+		t.Fatal("refresh ran while another was in progress")
+	}
+
+	srv.mu.Lock()
+	srv.refreshing = false
+	before := srv.lastRefresh[feedURL]
+	srv.mu.Unlock()
+
+	// The next refresh fetches the queued feed too
+	srv.Refresh(context.Background(), nil)
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if !srv.lastRefresh[feedURL].After(before) || len(srv.pending) != 0 {
+		t.Errorf("queued feed wasn't refreshed, pending %v", srv.pending)
+	}
+}
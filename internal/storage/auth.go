@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Secret is a credential in the config. It's either the value itself, or a
+// reference resolved when first needed so the value isn't stored in
+// plaintext: "env:NAME" reads an environment variable and "cmd:COMMAND"
+// runs a shell command (e.g. "cmd:pass show feeds/example") and uses the
+// first line of its output.
+type Secret string
+
+// Resolve returns the secret's value
+func (s Secret) Resolve() (string, error) {
+	value := string(s)
+	switch {
+	case strings.HasPrefix(value, "env:"):
+		name := strings.TrimPrefix(value, "env:")
+		resolved, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return resolved, nil
+	case strings.HasPrefix(value, "cmd:"):
+		command := strings.TrimPrefix(value, "cmd:")
+		output, err := exec.Command("sh", "-c", command).Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to run %q: %v", command, err)
+		}
+		line, _, _ := strings.Cut(string(output), "\n")
+		return strings.TrimRight(line, "\r"), nil
+	}
+	return value, nil
+}
+
+// FeedAuth holds the credentials of a feed
+type FeedAuth struct {
+	Type     string `json:"type"` // "basic", "bearer" or "cookie"
+	Username string `json:"username,omitempty"`
+	Password Secret `json:"password,omitempty"` // For basic auth
+	Token    Secret `json:"token,omitempty"`    // Bearer token, or the Cookie header value
+}
+
+// feedCredentials implements feed.Credentials for a feed's auth and custom
+// headers, resolving the secrets on first use
+type feedCredentials struct {
+	auth    *FeedAuth
+	headers map[string]Secret
+
+	once   sync.Once
+	header http.Header
+	err    error
+}
+
+func (c *feedCredentials) Header() (http.Header, error) {
+	c.once.Do(func() {
+		c.header, c.err = c.resolve()
+	})
+	return c.header, c.err
+}
+
+func (c *feedCredentials) resolve() (http.Header, error) {
+	header := make(http.Header)
+	for name, secret := range c.headers {
+		value, err := secret.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %v", name, err)
+		}
+		header.Set(name, value)
+	}
+
+	if c.auth == nil {
+		return header, nil
+	}
+
+	switch strings.ToLower(c.auth.Type) {
+	case "basic":
+		password, err := c.auth.Password.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("password: %v", err)
+		}
+		token := base64.StdEncoding.EncodeToString([]byte(c.auth.Username + ":" + password))
+		header.Set("Authorization", "Basic "+token)
+	case "bearer":
+		token, err := c.auth.Token.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("token: %v", err)
+		}
+		header.Set("Authorization", "Bearer "+token)
+	case "cookie":
+		cookie, err := c.auth.Token.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("cookie: %v", err)
+		}
+		header.Set("Cookie", cookie)
+	default:
+		return nil, fmt.Errorf("unknown auth type %q", c.auth.Type)
+	}
+
+	return header, nil
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"bloom/internal/feed"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSecretResolve(t *testing.T) {
+	t.Setenv("BLOOM_TEST_TOKEN", "from-env")
+
+	tests := []struct {
+		secret  Secret
+		want    string
+		wantErr bool
+	}{
+		{"plain", "plain", false},
+		{"env:BLOOM_TEST_TOKEN", "from-env", false},
+		{"env:BLOOM_TEST_UNSET", "", true},
+		{"cmd:printf 'from-cmd\\nsecond line'", "from-cmd", false},
+		{"cmd:exit 1", "", true},
+	}
+	for _, tt := range tests {
+		got, err := tt.secret.Resolve()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("Resolve(%q) = %q, %v", tt.secret, got, err)
+		}
+	}
+}
+
+func TestConfigureClientsSendsCredentials(t *testing.T) {
+	t.Setenv("BLOOM_TEST_PASSWORD", "s3cret")
+
+	var feedAuth, articleAuth, apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.xml":
+			feedAuth = r.Header.Get("Authorization")
+			apiKey = r.Header.Get("X-Api-Key")
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte(`<rss version="2.0"><channel><title>Private</title></channel></rss>`))
+		case "/article":
+			articleAuth = r.Header.Get("Authorization")
+			w.Write([]byte(`<html><body><article><p>Members only.</p></article></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	feedURL := server.URL + "/feed.xml"
+	config := &Config{Feeds: []FeedConfig{{
+		URL:     feedURL,
+		Auth:    &FeedAuth{Type: "basic", Username: "reader", Password: "env:BLOOM_TEST_PASSWORD"},
+		Headers: map[string]Secret{"X-Api-Key": "key-123"},
+	}}}
+
+	reader := feed.NewReader()
+	fetcher := feed.NewArticleFetcher()
+	if err := config.ConfigureClients(feed.NewClientFactory(), reader, fetcher); err != nil {
+		t.Fatalf("ConfigureClients: %v", err)
+	}
+
+	if _, err := reader.Read(feedURL); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	const want = "Basic cmVhZGVyOnMzY3JldA==" // reader:s3cret
+	if feedAuth != want || apiKey != "key-123" {
+		t.Errorf("feed request had Authorization %q, X-Api-Key %q", feedAuth, apiKey)
+	}
+
+	if _, err := fetcher.ExtractFromFeed(feedURL, server.URL+"/article"); err != nil {
+		t.Fatalf("ExtractFromFeed: %v", err)
+	}
+	if articleAuth != want {
+		t.Errorf("article request had Authorization %q", articleAuth)
+	}
+
+	// Other feeds' articles don't get the credentials
+	articleAuth = ""
+	if _, err := fetcher.ExtractFromFeed("https://other.example.com/feed", server.URL+"/article"); err != nil {
+		t.Fatalf("ExtractFromFeed: %v", err)
+	}
+	if articleAuth != "" {
+		t.Errorf("credentials leaked to another feed's article: %q", articleAuth)
+	}
+}
+
+func TestConfigureClientsSkipsOnlyFeedsThatFail(t *testing.T) {
+	var apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.Header.Get("X-Api-Key")
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`<rss version="2.0"><channel><title>Private</title></channel></rss>`))
+	}))
+	defer server.Close()
+
+	feedURL := server.URL + "/feed.xml"
+	config := &Config{Feeds: []FeedConfig{
+		{URL: "https://broken.example.com/feed", HTTP: &HTTPSettings{Proxy: "ftp://proxy.example.com"}},
+		{URL: feedURL, Headers: map[string]Secret{"X-Api-Key": "key-123"}},
+	}}
+
+	reader := feed.NewReader()
+	err := config.ConfigureClients(feed.NewClientFactory(), reader, feed.NewArticleFetcher())
+	if err == nil || !strings.Contains(err.Error(), "broken.example.com") {
+		t.Errorf("expected an error naming the broken feed, got %v", err)
+	}
+
+	if _, err := reader.Read(feedURL); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if apiKey != "key-123" {
+		t.Errorf("feed request had X-Api-Key %q", apiKey)
+	}
+}
+
+func TestDiscoveryClientUsesCredentialsOfTheSite(t *testing.T) {
+	var apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.Header.Get("X-Api-Key")
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`<rss version="2.0"><channel><title>Private</title></channel></rss>`))
+	}))
+	defer server.Close()
+
+	config := &Config{Feeds: []FeedConfig{{
+		URL:     server.URL + "/feed.xml",
+		Headers: map[string]Secret{"X-Api-Key": "key-123"},
+	}}}
+
+	client, err := config.DiscoveryClient(feed.NewClientFactory(), server.URL+"/comments.xml")
+	if err != nil || client == nil {
+		t.Fatalf("expected the configured feed's client, got %v, %v", client, err)
+	}
+	if _, err := feed.NewReader().DiscoverWith(client, server.URL+"/comments.xml"); err != nil {
+		t.Fatalf("DiscoverWith: %v", err)
+	}
+	if apiKey != "key-123" {
+		t.Errorf("discovery request had X-Api-Key %q", apiKey)
+	}
+
+	if client, _ := config.DiscoveryClient(feed.NewClientFactory(), "https://other.example.com/"); client != nil {
+		t.Error("expected no credentials for another host")
+	}
+}
@@ -2,39 +2,82 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // FeedConfig represents a feed configuration
 type FeedConfig struct {
-	URL      string
-	Category string
-	Tags     []string
+	URL      string   `json:"url"`
+	Title    string   `json:"title,omitempty"` // Shown instead of the feed's own title
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+
+	// Paused feeds aren't refreshed until resumed
+	Paused bool `json:"paused,omitempty"`
+
+	// RefreshIntervalMin overrides Config.RefreshIntervalMin when set
+	RefreshIntervalMin int `json:"refresh_interval_min,omitempty"`
+
+	// MaxItems caps how many items are kept, newest first; 0 keeps all
+	MaxItems int `json:"max_items,omitempty"`
+
+	// OpenAction is what opening an item does, one of the Open* constants;
+	// empty means OpenExtract
+	OpenAction string `json:"open_action,omitempty"`
+
+	// MarkReadOnView overrides Config.MarkReadOnView when set
+	MarkReadOnView *bool `json:"mark_read_on_view,omitempty"`
+
+	// Notify sends a desktop notification when new items arrive
+	Notify bool `json:"notify,omitempty"`
+
+	// HTTP overrides the fields it sets of Config.HTTP for this feed
+	HTTP *HTTPSettings `json:"http,omitempty"`
+
+	// Auth and Headers are sent with the feed's requests, and with article
+	// requests to the feed's host
+	Auth    *FeedAuth         `json:"auth,omitempty"`
+	Headers map[string]Secret `json:"headers,omitempty"`
+
+	// Disabled feeds aren't fetched; set when a feed is gone (410)
+	Disabled bool `json:"disabled,omitempty"`
 }
 
 // Config represents the application configuration
 type Config struct {
+	Version            int          `json:"version"` // See CurrentConfigVersion
 	Feeds              []FeedConfig `json:"feeds"`
 	AutoSave           bool         `json:"auto_save"`
 	MarkReadOnView     bool         `json:"mark_read_on_view"`
 	DefaultCategory    string       `json:"default_category"`
 	RefreshIntervalMin int          `json:"refresh_interval_min"`
+	HTTP               HTTPSettings `json:"http"`
+
+	// ReadRetentionDays is how long read marks are kept for items that are
+	// no longer in any feed: 0 means DefaultReadRetentionDays, negative
+	// keeps them forever
+	ReadRetentionDays int `json:"read_retention_days,omitempty"`
+
+	// saved are the feeds as last loaded or saved, the base SaveConfig
+	// merges changes made to the file since with
+	saved []FeedConfig
 }
 
-// LoadConfig loads the configuration from ~/.config/bloom/config.json
+// LoadConfig loads the configuration from the file given by GetConfigPath
 func LoadConfig() (*Config, error) {
-	// Get config directory path
-	homeDir, err := os.UserHomeDir()
+	configPath, err := GetConfigPath()
 	if err != nil {
 		return DefaultConfig(), nil // Return default config if can't find home
 	}
-
-	configDir := filepath.Join(homeDir, ".config", "bloom")
-	configPath := filepath.Join(configDir, "config.json")
+	if legacy, ok := legacyPath(configPath, "config.json"); ok {
+		configPath = legacy
+	}
 
 	// Check if file exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
@@ -53,10 +96,18 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %v", err)
 	}
 
-	var config Config
-	err = json.Unmarshal(data, &config)
+	config, migrated, err := parseConfig(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %v", err)
+		// A file from a newer bloom is left alone rather than replaced
+		var newer *newerConfigError
+		if errors.As(err, &newer) {
+			return nil, &ConfigError{Path: configPath, Err: err}
+		}
+		backup, backupErr := backupConfig(configPath, data)
+		if backupErr != nil {
+			return nil, &ConfigError{Path: configPath, Err: err}
+		}
+		return DefaultConfig(), &ConfigError{Path: configPath, Backup: backup, Err: err}
 	}
 
 	// Ensure feeds slice is initialized
@@ -65,28 +116,34 @@ func LoadConfig() (*Config, error) {
 	}
 
 	// Normalize feed URLs (add https:// if missing)
-	needsSave := false
+	needsSave := migrated
 	for i := range config.Feeds {
-		normalized := normalizeFeedURL(config.Feeds[i].URL)
+		normalized := NormalizeFeedURL(config.Feeds[i].URL)
 		if normalized != config.Feeds[i].URL {
 			config.Feeds[i].URL = normalized
 			needsSave = true
 		}
 	}
 	
-	// Save config with normalized URLs if any changed
+	config.saved = cloneFeeds(config.Feeds)
+
+	// Save config with normalized URLs, or in the current format, if any changed
 	if needsSave {
-		if err := SaveConfig(&config); err != nil {
+		if err := SaveConfig(config); err != nil {
 			// Log error but don't fail - config is still valid
-			fmt.Printf("Warning: failed to save normalized URLs: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Warning: failed to save normalized URLs: %v\n", err)
 		}
 	}
 
-	return &config, nil
+	// Problems are reported, but the config is still usable
+	if err := config.Validate(); err != nil {
+		return config, &ConfigError{Path: configPath, Err: err}
+	}
+	return config, nil
 }
 
-// normalizeFeedURL normalizes a feed URL by adding https:// if no protocol is present
-func normalizeFeedURL(rawURL string) string {
+// NormalizeFeedURL normalizes a feed URL by adding https:// if no protocol is present
+func NormalizeFeedURL(rawURL string) string {
 	rawURL = strings.TrimSpace(rawURL)
 	if rawURL == "" {
 		return rawURL
@@ -107,34 +164,45 @@ func normalizeFeedURL(rawURL string) string {
 	return rawURL
 }
 
-// SaveConfig saves the configuration to ~/.config/bloom/config.json
+// SaveConfig saves the configuration to the file given by GetConfigPath.
+// Feeds another bloom instance added, changed or removed in the file since
+// config was loaded are kept that way, unless config changed them too.
 func SaveConfig(config *Config) error {
-	// Get config directory path
-	homeDir, err := os.UserHomeDir()
+	configPath, err := GetConfigPath()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %v", err)
+		return fmt.Errorf("failed to get config path: %v", err)
 	}
 
-	configDir := filepath.Join(homeDir, ".config", "bloom")
-	configPath := filepath.Join(configDir, "config.json")
-
 	// Create config directory if it doesn't exist
-	err = os.MkdirAll(configDir, 0755)
+	err = os.MkdirAll(filepath.Dir(configPath), 0755)
 	if err != nil {
 		return fmt.Errorf("failed to create config directory: %v", err)
 	}
 
-	// Marshal config to JSON
-	data, err := json.MarshalIndent(config, "", "  ")
+	unlock, err := lockFile(configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Marshal config to JSON, with the feeds merged with those on disk
+	config.Version = CurrentConfigVersion
+	merged := *config
+	if config.saved != nil {
+		if onDisk, ok := readConfigFeeds(configPath); ok {
+			merged.Feeds = mergeFeeds(config.saved, config.Feeds, onDisk)
+		}
+	}
+	data, err := json.MarshalIndent(&merged, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %v", err)
 	}
 
-	// Write to file
-	err = os.WriteFile(configPath, data, 0644)
+	err = writeFileAtomic(configPath, data, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write config file: %v", err)
 	}
+	config.saved = cloneFeeds(config.Feeds)
 
 	return nil
 }
@@ -142,6 +210,7 @@ func SaveConfig(config *Config) error {
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
+		Version: CurrentConfigVersion,
 		Feeds: []FeedConfig{
 			{
 				URL:      "https://mitchellh.com/feed.xml",
@@ -156,12 +225,50 @@ func DefaultConfig() *Config {
 	}
 }
 
-// GetConfigPath returns the path to the config file
-func GetConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+// RemoveFeed removes the feed with the given URL, reporting whether it was
+// configured
+func (c *Config) RemoveFeed(feedURL string) bool {
+	feedURL = NormalizeFeedURL(feedURL)
+	for i, feed := range c.Feeds {
+		if NormalizeFeedURL(feed.URL) == feedURL {
+			c.Feeds = append(c.Feeds[:i], c.Feeds[i+1:]...)
+			return true
+		}
 	}
-	return filepath.Join(homeDir, ".config", "bloom", "config.json"), nil
+	return false
 }
 
+// MergeFeeds appends the feeds whose URL isn't configured yet and returns
+// the ones that were added
+func (c *Config) MergeFeeds(feeds []FeedConfig) []FeedConfig {
+	existing := make(map[string]bool, len(c.Feeds))
+	for _, feed := range c.Feeds {
+		existing[NormalizeFeedURL(feed.URL)] = true
+	}
+
+	var added []FeedConfig
+	for _, feed := range feeds {
+		url := NormalizeFeedURL(feed.URL)
+		if url == "" || existing[url] {
+			continue
+		}
+		existing[url] = true
+		feed.URL = url
+		c.Feeds = append(c.Feeds, feed)
+		added = append(added, feed)
+	}
+	return added
+}
+
+// RefreshInterval returns how often a feed is refreshed in the background,
+// or 0 if it isn't
+func (c *Config) RefreshInterval(feed FeedConfig) time.Duration {
+	minutes := c.RefreshIntervalMin
+	if feed.RefreshIntervalMin > 0 {
+		minutes = feed.RefreshIntervalMin
+	}
+	if minutes <= 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+)
+
+// cloneFeeds returns a deep copy of feeds
+func cloneFeeds(feeds []FeedConfig) []FeedConfig {
+	data, err := json.Marshal(feeds)
+	if err != nil {
+		return nil
+	}
+	var clone []FeedConfig
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil
+	}
+	return clone
+}
+
+// Clone returns a copy of the config to save while c goes on changing
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.Feeds = cloneFeeds(c.Feeds)
+	return &clone
+}
+
+// readConfigFeeds returns the feeds of the config file at path, with
+// normalized URLs, or false if it can't be read
+func readConfigFeeds(path string) ([]FeedConfig, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	config, _, err := parseConfig(data)
+	if err != nil {
+		return nil, false
+	}
+	for i := range config.Feeds {
+		config.Feeds[i].URL = NormalizeFeedURL(config.Feeds[i].URL)
+	}
+	return config.Feeds, true
+}
+
+// mergeFeeds merges the feeds of a config with those in its file, both
+// changed since base was loaded. Feeds are matched by URL. A feed left as it
+// was in base takes the file's version, or is dropped if the file dropped
+// it; otherwise the config's version wins. Feeds only in the file, added
+// there since, are appended.
+func mergeFeeds(base, mine, onDisk []FeedConfig) []FeedConfig {
+	byURL := func(feeds []FeedConfig) map[string]FeedConfig {
+		m := make(map[string]FeedConfig, len(feeds))
+		for _, feed := range feeds {
+			m[feed.URL] = feed
+		}
+		return m
+	}
+	baseFeeds, myFeeds, diskFeeds := byURL(base), byURL(mine), byURL(onDisk)
+
+	merged := []FeedConfig{}
+	for _, feed := range mine {
+		old, inBase := baseFeeds[feed.URL]
+		theirs, onDisk := diskFeeds[feed.URL]
+		unchanged := inBase && reflect.DeepEqual(feed, old)
+		switch {
+		case unchanged && !onDisk:
+			continue
+		case unchanged:
+			merged = append(merged, theirs)
+		default:
+			merged = append(merged, feed)
+		}
+	}
+	for _, feed := range onDisk {
+		_, inBase := baseFeeds[feed.URL]
+		_, inMine := myFeeds[feed.URL]
+		if !inBase && !inMine {
+			merged = append(merged, feed)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CurrentConfigVersion is the version of the config format written by
+// SaveConfig. Files without a version are version 1.
+const CurrentConfigVersion = 2
+
+// configMigrations[i] upgrades a decoded config file from version i+1 to
+// version i+2
+var configMigrations = []func(raw map[string]any) error{
+	migrateFeedKeys,
+}
+
+// ConfigError is returned by LoadConfig alongside a working config when the
+// file couldn't be used as written
+type ConfigError struct {
+	Path   string
+	Backup string // Copy of a file that couldn't be parsed, replaced by the defaults
+	Err    error
+}
+
+func (e *ConfigError) Error() string {
+	if e.Backup != "" {
+		return fmt.Sprintf("%s: %v (using the defaults, the file was copied to %s)", e.Path, e.Err, e.Backup)
+	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// newerConfigError is returned for a file written by a newer bloom
+type newerConfigError struct {
+	version int
+}
+
+func (e *newerConfigError) Error() string {
+	return fmt.Sprintf("version %d is newer than this bloom supports (%d)", e.version, CurrentConfigVersion)
+}
+
+// parseConfig decodes a config file, upgrading it from older versions. It
+// reports whether the file was migrated and should be saved again.
+func parseConfig(data []byte) (*Config, bool, error) {
+	var raw map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // Keep numbers as written through the migrations
+	if err := dec.Decode(&raw); err != nil {
+		return nil, false, describeJSONError(data, err)
+	}
+
+	version := 1
+	if v, ok := raw["version"]; ok {
+		n, ok := v.(json.Number)
+		parsed, err := n.Int64()
+		if !ok || err != nil || parsed < 1 {
+			return nil, false, fmt.Errorf("version: expected a positive whole number, got %v", v)
+		}
+		version = int(parsed)
+	}
+	if version > CurrentConfigVersion {
+		return nil, false, &newerConfigError{version}
+	}
+
+	for v := version; v < CurrentConfigVersion; v++ {
+		if err := configMigrations[v-1](raw); err != nil {
+			return nil, false, fmt.Errorf("failed to migrate from version %d: %v", v, err)
+		}
+	}
+	raw["version"] = CurrentConfigVersion
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal migrated config: %v", err)
+	}
+	var config Config
+	if err := json.Unmarshal(migrated, &config); err != nil {
+		return nil, false, describeJSONError(migrated, err)
+	}
+	return &config, version < CurrentConfigVersion, nil
+}
+
+// describeJSONError names where a config file is broken: the line and
+// column of a syntax error, or the field of a value of the wrong type
+// (e.g. "feeds[3].url")
+func describeJSONError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		before := data[:min(int(syntaxErr.Offset), len(data))]
+		line := bytes.Count(before, []byte("\n")) + 1
+		column := len(before) - bytes.LastIndexByte(before, '\n')
+		return fmt.Errorf("line %d, column %d: %v", line, column, err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return fmt.Errorf("%s: expected %s, got %s", fieldPath(typeErr.Field), typeErr.Type, typeErr.Value)
+	}
+	return err
+}
+
+// fieldPath writes the array indices in a field path of the json package
+// the way Validate does: "feeds.3.url" becomes "feeds[3].url"
+func fieldPath(field string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(part); err == nil {
+			b.WriteString("[" + part + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// backupConfig copies a config file that couldn't be parsed next to it, so
+// saving the working config doesn't lose it. It returns the copy's path.
+func backupConfig(path string, data []byte) (string, error) {
+	backup := path + ".bad-" + time.Now().Format("20060102-150405")
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to back up config file: %v", err)
+	}
+	return backup, nil
+}
+
+// migrateFeedKeys renames the feed keys of version 1, which were the Go
+// field names, to snake_case
+func migrateFeedKeys(raw map[string]any) error {
+	renames := map[string]string{
+		"URL":                "url",
+		"Category":           "category",
+		"Tags":               "tags",
+		"RefreshIntervalMin": "refresh_interval_min",
+		"HTTP":               "http",
+		"Auth":               "auth",
+		"Headers":            "headers",
+		"Disabled":           "disabled",
+	}
+
+	feeds, ok := raw["feeds"].([]any)
+	if !ok {
+		return nil
+	}
+	for i, entry := range feeds {
+		feed, ok := entry.(map[string]any)
+		if !ok {
+			return fmt.Errorf("feeds[%d]: expected an object", i)
+		}
+		for key, value := range feed {
+			renamed, ok := renames[key]
+			if !ok {
+				// encoding/json matched keys case-insensitively
+				renamed, ok = renames[caseInsensitiveKey(renames, key)]
+			}
+			if ok && renamed != key {
+				delete(feed, key)
+				feed[renamed] = value
+			}
+		}
+	}
+	return nil
+}
+
+// caseInsensitiveKey returns the key of m equal to key ignoring case
+func caseInsensitiveKey(m map[string]string, key string) string {
+	for k := range m {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+	}
+	return ""
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file for LoadConfig and returns its path
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(ConfigEnv, path)
+	return path
+}
+
+func TestLoadConfigMigratesVersion1(t *testing.T) {
+	path := writeConfig(t, `{
+  "feeds": [{"URL": "https://example.com/feed.xml", "Category": "News", "Tags": ["a"], "RefreshIntervalMin": 15, "Disabled": true}],
+  "refresh_interval_min": 60
+}`)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed := config.Feeds[0]
+	if feed.URL != "https://example.com/feed.xml" || feed.Category != "News" || feed.RefreshIntervalMin != 15 || !feed.Disabled {
+		t.Errorf("feed not migrated: %+v", feed)
+	}
+
+	// Saved back in the current format
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"version": 2`, `"url":`, `"refresh_interval_min": 15`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s in the saved config:\n%s", want, data)
+		}
+	}
+}
+
+func TestLoadConfigReportsInvalidFields(t *testing.T) {
+	writeConfig(t, `{
+  "version": 2,
+  "feeds": [
+    {"url": "https://example.com/feed.xml", "http": {"proxy": "socks5h://127.0.0.1:1080"}},
+    {"url": "https:///feed.xml"},
+    {"url": "https://example.com/feed.xml", "auth": {"type": "digest"}}
+  ],
+  "http": {"proxy": "ftp://proxy"}
+}`)
+
+	config, err := LoadConfig()
+	if config == nil || len(config.Feeds) != 3 {
+		t.Fatalf("expected the config to load despite its problems: %v", err)
+	}
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Backup != "" {
+		t.Fatalf("expected a ConfigError without a backup, got %v", err)
+	}
+	for _, want := range []string{
+		`http.proxy: unsupported scheme "ftp"`,
+		`feeds[1].url: missing host`,
+		`feeds[2].url: duplicate of feeds[0]`,
+		`feeds[2].auth.type: must be basic, bearer or cookie, got "digest"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "feeds[0].http") {
+		t.Errorf("expected the socks5h proxy to be accepted: %v", err)
+	}
+}
+
+func TestLoadConfigRecoversFromParseError(t *testing.T) {
+	bad := "{\n  \"feeds\": [\n    {\"url\": \"https://example.com/feed.xml\",}\n  ]\n}"
+	path := writeConfig(t, bad)
+
+	config, err := LoadConfig()
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Backup == "" {
+		t.Fatalf("expected a ConfigError with a backup, got %v", err)
+	}
+	if config == nil || len(config.Feeds) != len(DefaultConfig().Feeds) {
+		t.Errorf("expected the default config, got %+v", config)
+	}
+	if !strings.Contains(err.Error(), "line 3, column") {
+		t.Errorf("expected the error to name the line: %v", err)
+	}
+
+	backup, err := os.ReadFile(configErr.Backup)
+	if err != nil || string(backup) != bad {
+		t.Errorf("expected the bad file to be backed up, got %q, %v", backup, err)
+	}
+	// The original stays until the working config is saved over it
+	if data, _ := os.ReadFile(path); string(data) != bad {
+		t.Errorf("expected the bad file to be left in place, got %q", data)
+	}
+}
+
+func TestLoadConfigNamesTheFeedWithAValueOfTheWrongType(t *testing.T) {
+	writeConfig(t, `{
+  "version": 2,
+  "feeds": [
+    {"url": "https://example.com/a.xml"},
+    {"url": "https://example.com/b.xml"},
+    {"url": "https://example.com/c.xml", "auth": {"type": "basic", "username": 42}}
+  ]
+}`)
+
+	_, err := LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), "feeds[2].auth.username: expected string") {
+		t.Errorf("expected the error to name feeds[2], got %v", err)
+	}
+}
+
+func TestLoadConfigLeavesNewerVersionAlone(t *testing.T) {
+	path := writeConfig(t, `{"version": 99, "feeds": []}`)
+
+	config, err := LoadConfig()
+	if config != nil || err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected an error for a newer config, got %+v, %v", config, err)
+	}
+	matches, _ := filepath.Glob(path + ".bad-*")
+	if len(matches) != 0 {
+		t.Errorf("expected no backup of a newer config: %v", matches)
+	}
+}
+
+func TestSaveConfigMergesFeedsChangedElsewhere(t *testing.T) {
+	writeConfig(t, `{
+  "version": 2,
+  "feeds": [
+    {"url": "https://example.com/a.xml"},
+    {"url": "https://example.com/b.xml"},
+    {"url": "https://example.com/c.xml"}
+  ]
+}`)
+
+	// A TUI session and a cron refresh load the same file
+	tui, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cron, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tui.Feeds = append(tui.Feeds, FeedConfig{URL: "https://example.com/new.xml"})
+	tui.Feeds[0].Category = "Edited"
+	if err := SaveConfig(tui); err != nil {
+		t.Fatal(err)
+	}
+
+	// The refresh found b gone and c moved, knowing nothing of the TUI's changes
+	cron.Feeds[1].Disabled = true
+	cron.Feeds[2].URL = "https://example.com/c2.xml"
+	if err := SaveConfig(cron); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for _, feed := range saved.Feeds {
+		urls = append(urls, feed.URL)
+	}
+	want := "https://example.com/a.xml https://example.com/b.xml https://example.com/c2.xml https://example.com/new.xml"
+	if got := strings.Join(urls, " "); got != want {
+		t.Fatalf("feeds = %s, want %s", got, want)
+	}
+	if saved.Feeds[0].Category != "Edited" || !saved.Feeds[1].Disabled {
+		t.Errorf("expected both instances' changes: %+v", saved.Feeds)
+	}
+}
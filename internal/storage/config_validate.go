@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"bloom/internal/feed"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// FieldError is a problem with one field of the config, named by its path
+// in the file, e.g. "feeds[3].url: missing host"
+type FieldError struct {
+	Field   string
+	Problem string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Problem
+}
+
+// Validate checks the config for values bloom can't use. It returns the
+// FieldErrors found, joined with errors.Join.
+func (c *Config) Validate() error {
+	var errs []error
+	problem := func(field, format string, args ...any) {
+		errs = append(errs, &FieldError{Field: field, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	if c.RefreshIntervalMin < 0 {
+		problem("refresh_interval_min", "must not be negative")
+	}
+	c.HTTP.validate("http", problem)
+
+	seen := make(map[string]int, len(c.Feeds))
+	for i, feed := range c.Feeds {
+		prefix := fmt.Sprintf("feeds[%d]", i)
+
+		if err := validateFeedURL(feed.URL); err != nil {
+			problem(prefix+".url", "%v", err)
+		} else if j, ok := seen[feed.URL]; ok {
+			problem(prefix+".url", "duplicate of feeds[%d]", j)
+		} else {
+			seen[feed.URL] = i
+		}
+
+		if feed.RefreshIntervalMin < 0 {
+			problem(prefix+".refresh_interval_min", "must not be negative")
+		}
+		if feed.MaxItems < 0 {
+			problem(prefix+".max_items", "must not be negative")
+		}
+		if feed.OpenAction != "" && !slices.Contains(OpenActions, feed.OpenAction) {
+			problem(prefix+".open_action", "must be %s or %s, got %q",
+				strings.Join(OpenActions[:len(OpenActions)-1], ", "), OpenActions[len(OpenActions)-1], feed.OpenAction)
+		}
+		if feed.HTTP != nil {
+			feed.HTTP.validate(prefix+".http", problem)
+		}
+		if auth := feed.Auth; auth != nil {
+			switch auth.Type {
+			case "basic":
+				if auth.Username == "" {
+					problem(prefix+".auth.username", "required for basic auth")
+				}
+			case "bearer", "cookie":
+				if auth.Token == "" {
+					problem(prefix+".auth.token", "required for %s auth", auth.Type)
+				}
+			default:
+				problem(prefix+".auth.type", "must be basic, bearer or cookie, got %q", auth.Type)
+			}
+		}
+		for name := range feed.Headers {
+			if name == "" {
+				problem(prefix+".headers", "empty header name")
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateFeedURL checks that a feed URL can be fetched
+func validateFeedURL(raw string) error {
+	if raw == "" {
+		return errors.New("missing")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return errors.New("not a valid URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("missing host")
+	}
+	return nil
+}
+
+// validate checks HTTP settings found at field
+func (s HTTPSettings) validate(field string, problem func(field, format string, args ...any)) {
+	if s.Proxy != "" {
+		if u, err := url.Parse(s.Proxy); err != nil || u.Host == "" {
+			problem(field+".proxy", "not a valid URL")
+		} else if !slices.Contains(feed.ProxySchemes, u.Scheme) {
+			problem(field+".proxy", "unsupported scheme %q", u.Scheme)
+		}
+	}
+	for _, f := range []struct {
+		name  string
+		value int
+	}{
+		{"timeout_sec", s.TimeoutSec},
+		{"connect_timeout_sec", s.ConnectTimeoutSec},
+		{"retry_backoff_ms", s.RetryBackoffMs},
+		{"max_retry_delay_sec", s.MaxRetryDelaySec},
+	} {
+		if f.value < 0 {
+			problem(field+"."+f.name, "must not be negative")
+		}
+	}
+	if s.Retries != nil && *s.Retries < 0 {
+		problem(field+".retries", "must not be negative")
+	}
+}
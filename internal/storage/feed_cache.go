@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"bloom/internal/feed"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FeedCache persists the last fetched channel and its HTTP validators
+// (ETag, Last-Modified) for each feed in the directory given by GetCacheDir.
+// It implements feed.Cache.
+type FeedCache struct {
+	dir string
+
+	mu      sync.Mutex
+	entries map[string][]byte // Encoded entries, so every Get returns a fresh copy
+}
+
+// cachedFeed is the on-disk representation of a cache entry
+type cachedFeed struct {
+	URL string
+	feed.CacheEntry
+}
+
+// NewFeedCache creates a cache in the default cache directory. If the home
+// directory can't be determined the cache only lives in memory.
+func NewFeedCache() *FeedCache {
+	dir, err := GetCacheDir()
+	if err != nil {
+		dir = ""
+	}
+	return &FeedCache{
+		dir:     dir,
+		entries: make(map[string][]byte),
+	}
+}
+
+// Get returns the cached entry for a feed URL
+func (c *FeedCache) Get(feedURL string) (*feed.CacheEntry, bool) {
+	c.mu.Lock()
+	data, ok := c.entries[feedURL]
+	c.mu.Unlock()
+
+	if !ok && c.dir != "" {
+		var err error
+		data, err = os.ReadFile(c.path(feedURL))
+		if err != nil {
+			return nil, false
+		}
+		c.mu.Lock()
+		c.entries[feedURL] = data
+		c.mu.Unlock()
+	}
+	if data == nil {
+		return nil, false
+	}
+
+	var cached cachedFeed
+	if err := json.Unmarshal(data, &cached); err != nil || cached.URL != feedURL {
+		return nil, false
+	}
+	return &cached.CacheEntry, true
+}
+
+// Put stores the entry for a feed URL, in memory and on disk
+func (c *FeedCache) Put(feedURL string, entry *feed.CacheEntry) error {
+	data, err := json.Marshal(cachedFeed{URL: feedURL, CacheEntry: *entry})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %v", err)
+	}
+
+	c.mu.Lock()
+	c.entries[feedURL] = data
+	c.mu.Unlock()
+
+	if c.dir == "" {
+		return nil
+	}
+
+	err = os.MkdirAll(c.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+
+	err = writeFileAtomic(c.path(feedURL), data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write cache file: %v", err)
+	}
+
+	return nil
+}
+
+// Move stores the entry of a feed under its new URL
+func (c *FeedCache) Move(oldURL, newURL string) error {
+	entry, ok := c.Get(oldURL)
+	if !ok {
+		return nil
+	}
+	return c.Put(newURL, entry)
+}
+
+// path returns the cache file for a feed URL
+func (c *FeedCache) path(feedURL string) string {
+	sum := sha256.Sum256([]byte(feedURL))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:12])+".json")
+}
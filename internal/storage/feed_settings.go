@@ -0,0 +1,64 @@
+package storage
+
+import "bloom/internal/feed"
+
+// What opening an item does, for FeedConfig.OpenAction
+const (
+	OpenExtract = "extract" // Fetch the page and show its main content
+	OpenContent = "content" // Show the content included in the feed
+	OpenBrowser = "browser" // Open the link in the browser
+)
+
+// OpenActions are the valid values of FeedConfig.OpenAction
+var OpenActions = []string{OpenExtract, OpenContent, OpenBrowser}
+
+// Enabled reports whether the feed is refreshed: it's neither paused nor
+// disabled because it's gone
+func (f FeedConfig) Enabled() bool {
+	return !f.Paused && !f.Disabled
+}
+
+// Open returns what opening one of the feed's items does
+func (f FeedConfig) Open() string {
+	if f.OpenAction == "" {
+		return OpenExtract
+	}
+	return f.OpenAction
+}
+
+// DisplayTitle returns the title override, or title, the feed's own
+func (f FeedConfig) DisplayTitle(title string) string {
+	if f.Title != "" {
+		return f.Title
+	}
+	return title
+}
+
+// LimitItems keeps the newest MaxItems of items, sorting them when some
+// are dropped
+func (f FeedConfig) LimitItems(items []feed.Item) []feed.Item {
+	if f.MaxItems <= 0 || len(items) <= f.MaxItems {
+		return items
+	}
+	feed.SortItems(items)
+	return items[:f.MaxItems]
+}
+
+// MarkReadOnViewFor reports whether viewing an item of the feed marks it
+// as read
+func (c *Config) MarkReadOnViewFor(feed FeedConfig) bool {
+	if feed.MarkReadOnView != nil {
+		return *feed.MarkReadOnView
+	}
+	return c.MarkReadOnView
+}
+
+// FindFeed returns the configured feed with the given URL
+func (c *Config) FindFeed(feedURL string) (FeedConfig, bool) {
+	for _, feed := range c.Feeds {
+		if feed.URL == feedURL {
+			return feed, true
+		}
+	}
+	return FeedConfig{}, false
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"bloom/internal/feed"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimitItemsKeepsNewest(t *testing.T) {
+	now := time.Now()
+	items := []feed.Item{
+		{Title: "old", Published: now.Add(-2 * time.Hour)},
+		{Title: "new", Published: now},
+		{Title: "middle", Published: now.Add(-time.Hour)},
+	}
+
+	kept := FeedConfig{MaxItems: 2}.LimitItems(items)
+	if len(kept) != 2 || kept[0].Title != "new" || kept[1].Title != "middle" {
+		t.Errorf("expected the two newest items, got %+v", kept)
+	}
+	if all := (FeedConfig{}).LimitItems(items); len(all) != 3 {
+		t.Errorf("expected every item without a limit, got %d", len(all))
+	}
+}
+
+func TestMarkReadOnViewFor(t *testing.T) {
+	config := &Config{MarkReadOnView: true}
+	off := false
+	if !config.MarkReadOnViewFor(FeedConfig{}) {
+		t.Error("expected the global setting without an override")
+	}
+	if config.MarkReadOnViewFor(FeedConfig{MarkReadOnView: &off}) {
+		t.Error("expected the feed's override to win")
+	}
+}
+
+func TestValidateFeedSettings(t *testing.T) {
+	config := &Config{Feeds: []FeedConfig{
+		{URL: "https://example.com/feed.xml", MaxItems: -1, OpenAction: "print"},
+	}}
+
+	err := config.Validate()
+	for _, want := range []string{
+		"feeds[0].max_items: must not be negative",
+		`feeds[0].open_action: must be extract, content or browser, got "print"`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
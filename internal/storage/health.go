@@ -0,0 +1,50 @@
+package storage
+
+import "time"
+
+// FeedHealth records how fetching a feed has been going
+type FeedHealth struct {
+	LastAttempt         time.Time
+	LastSuccess         time.Time
+	StatusCode          int    // HTTP status of the last attempt, 0 if there was no response
+	LastError           string // Error of the last attempt, empty if it succeeded
+	ConsecutiveFailures int
+
+	// MovedTo is where the feed has been permanently redirected to on the
+	// last PermanentRedirects fetches in a row
+	MovedTo            string `json:",omitempty"`
+	PermanentRedirects int    `json:",omitempty"`
+}
+
+// Failing reports whether the last attempt to fetch the feed failed
+func (h *FeedHealth) Failing() bool {
+	return h.ConsecutiveFailures > 0
+}
+
+// RecordFetch updates the health of a feed after an attempt to fetch it
+func (s *AppState) RecordFetch(feedURL string, at time.Time, statusCode int, err error) {
+	if s.FeedHealth == nil {
+		s.FeedHealth = make(map[string]*FeedHealth)
+	}
+	health, ok := s.FeedHealth[feedURL]
+	if !ok {
+		health = &FeedHealth{}
+		s.FeedHealth[feedURL] = health
+	}
+
+	health.LastAttempt = at
+	health.StatusCode = statusCode
+	if err != nil {
+		health.LastError = err.Error()
+		health.ConsecutiveFailures++
+		return
+	}
+	health.LastSuccess = at
+	health.LastError = ""
+	health.ConsecutiveFailures = 0
+}
+
+// Health returns the recorded health of a feed, or nil if it was never fetched
+func (s *AppState) Health(feedURL string) *FeedHealth {
+	return s.FeedHealth[feedURL]
+}
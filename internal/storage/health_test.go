@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecordFetch(t *testing.T) {
+	state := NewAppState()
+	const url = "https://example.com/feed.xml"
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	state.RecordFetch(url, start, 200, nil)
+	state.RecordFetch(url, start.Add(time.Hour), 503, errors.New("unexpected status code: 503"))
+	state.RecordFetch(url, start.Add(2*time.Hour), 0, errors.New("connection refused"))
+
+	health := state.Health(url)
+	if !health.Failing() || health.ConsecutiveFailures != 2 {
+		t.Fatalf("after two failures: %+v", health)
+	}
+	if health.StatusCode != 0 || health.LastError != "connection refused" {
+		t.Errorf("last attempt not recorded: %+v", health)
+	}
+	if !health.LastSuccess.Equal(start) || !health.LastAttempt.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("times = %v / %v", health.LastSuccess, health.LastAttempt)
+	}
+
+	state.RecordFetch(url, start.Add(3*time.Hour), 304, nil)
+	if health.Failing() || health.LastError != "" || !health.LastSuccess.Equal(start.Add(3*time.Hour)) {
+		t.Errorf("after recovering: %+v", health)
+	}
+}
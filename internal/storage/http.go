@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"bloom/internal/feed"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPSettings configures how feeds and articles are fetched. Empty fields
+// use the defaults of the feed package.
+type HTTPSettings struct {
+	UserAgent         string `json:"user_agent,omitempty"`
+	Proxy             string `json:"proxy,omitempty"` // http://, https://, socks5:// or socks5h:// URL
+	TimeoutSec        int    `json:"timeout_sec,omitempty"`
+	ConnectTimeoutSec int    `json:"connect_timeout_sec,omitempty"`
+	Retries           *int   `json:"retries,omitempty"` // 0 disables retries
+	RetryBackoffMs    int    `json:"retry_backoff_ms,omitempty"`
+	MaxRetryDelaySec  int    `json:"max_retry_delay_sec,omitempty"`
+}
+
+// Merge returns the settings with the fields set in override replacing
+// their counterparts
+func (s HTTPSettings) Merge(override *HTTPSettings) HTTPSettings {
+	if override == nil {
+		return s
+	}
+	if override.UserAgent != "" {
+		s.UserAgent = override.UserAgent
+	}
+	if override.Proxy != "" {
+		s.Proxy = override.Proxy
+	}
+	if override.TimeoutSec != 0 {
+		s.TimeoutSec = override.TimeoutSec
+	}
+	if override.ConnectTimeoutSec != 0 {
+		s.ConnectTimeoutSec = override.ConnectTimeoutSec
+	}
+	if override.Retries != nil {
+		s.Retries = override.Retries
+	}
+	if override.RetryBackoffMs != 0 {
+		s.RetryBackoffMs = override.RetryBackoffMs
+	}
+	if override.MaxRetryDelaySec != 0 {
+		s.MaxRetryDelaySec = override.MaxRetryDelaySec
+	}
+	return s
+}
+
+// ClientOptions converts the settings for feed.ClientFactory
+func (s HTTPSettings) ClientOptions() feed.ClientOptions {
+	opts := feed.ClientOptions{
+		UserAgent:      s.UserAgent,
+		Proxy:          s.Proxy,
+		Timeout:        time.Duration(s.TimeoutSec) * time.Second,
+		ConnectTimeout: time.Duration(s.ConnectTimeoutSec) * time.Second,
+		RetryBackoff:   time.Duration(s.RetryBackoffMs) * time.Millisecond,
+		MaxRetryDelay:  time.Duration(s.MaxRetryDelaySec) * time.Second,
+	}
+	if s.Retries != nil {
+		opts.MaxRetries = *s.Retries
+		if opts.MaxRetries == 0 {
+			opts.MaxRetries = -1 // feed.ClientOptions reads 0 as the default
+		}
+	}
+	return opts
+}
+
+// ConfigureClients points the reader and fetcher at clients built from the
+// config's HTTP settings, with a client of its own for each feed that
+// overrides them or needs credentials. Secrets are resolved on first use.
+// Clients that can't be built are left out and their errors joined; the
+// rest are still put to use.
+func (c *Config) ConfigureClients(factory *feed.ClientFactory, reader *feed.Reader, fetcher *feed.ArticleFetcher) error {
+	var errs []error
+	client, err := factory.Client(c.HTTP.ClientOptions())
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		reader.SetClient(client)
+		fetcher.SetClient(client)
+	}
+
+	feedClients := make(map[string]*feed.Client)
+	for _, feedConfig := range c.Feeds {
+		feedClient, err := c.feedClient(factory, feedConfig)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("feed %s: %v", feedConfig.URL, err))
+			continue
+		}
+		if feedClient != nil {
+			feedClients[feedConfig.URL] = feedClient
+		}
+	}
+	reader.SetFeedClients(feedClients)
+	fetcher.SetFeedClients(feedClients)
+
+	return errors.Join(errs...)
+}
+
+// feedClient builds the client of a feed that overrides the HTTP settings
+// or needs credentials. It returns nil for feeds using the shared client.
+func (c *Config) feedClient(factory *feed.ClientFactory, feedConfig FeedConfig) (*feed.Client, error) {
+	if feedConfig.HTTP == nil && !feedConfig.hasCredentials() {
+		return nil, nil
+	}
+
+	opts := c.HTTP.Merge(feedConfig.HTTP).ClientOptions()
+	if feedConfig.hasCredentials() {
+		opts.Credentials = &feedCredentials{auth: feedConfig.Auth, headers: feedConfig.Headers}
+	}
+	return factory.Client(opts)
+}
+
+func (f FeedConfig) hasCredentials() bool {
+	return f.Auth != nil || len(f.Headers) > 0
+}
+
+// DiscoveryClient returns the client to look for feeds at pageURL with. A
+// configured feed at that URL, or else one on the same host that needs
+// credentials, lends its client so further feeds of a private site can be
+// found. Otherwise it returns nil and the reader's client is used.
+func (c *Config) DiscoveryClient(factory *feed.ClientFactory, pageURL string) (*feed.Client, error) {
+	if feedConfig, ok := c.FindFeed(pageURL); ok {
+		return c.feedClient(factory, feedConfig)
+	}
+
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, nil
+	}
+	for _, feedConfig := range c.Feeds {
+		u, err := url.Parse(feedConfig.URL)
+		if err == nil && feedConfig.hasCredentials() && strings.EqualFold(u.Host, page.Host) {
+			return c.feedClient(factory, feedConfig)
+		}
+	}
+	return nil, nil
+}
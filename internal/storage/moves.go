@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"bloom/internal/feed"
+	"time"
+)
+
+// PermanentRedirectsBeforeMove is how many fetches in a row must be
+// permanently redirected to the same URL before a feed's URL is updated
+const PermanentRedirectsBeforeMove = 3
+
+// FetchOutcome describes what UpdateAfterFetch changed in the config
+type FetchOutcome struct {
+	MovedTo  string // The feed's new URL, if it moved
+	Disabled bool   // The feed is gone and was disabled
+}
+
+// ConfigChanged reports whether the config needs to be saved
+func (o FetchOutcome) ConfigChanged() bool {
+	return o.MovedTo != "" || o.Disabled
+}
+
+// UpdateAfterFetch records a fetch of a feed in the state and applies what
+// it means for the config: a feed that's been permanently redirected to the
+// same URL PermanentRedirectsBeforeMove times moves there, and a feed that's
+// gone (410) is disabled.
+func UpdateAfterFetch(config *Config, state *AppState, feedURL string, at time.Time, result feed.FetchResult, err error) FetchOutcome {
+	state.RecordFetch(feedURL, at, result.StatusCode, err)
+	health := state.Health(feedURL)
+
+	if feed.IsGone(err) {
+		return FetchOutcome{Disabled: config.DisableFeed(feedURL)}
+	}
+
+	// Only count redirects to a URL that actually serves the feed
+	if err != nil || result.MovedTo == "" || result.MovedTo == feedURL {
+		health.MovedTo = ""
+		health.PermanentRedirects = 0
+		return FetchOutcome{}
+	}
+	if health.MovedTo != result.MovedTo {
+		health.MovedTo = result.MovedTo
+		health.PermanentRedirects = 0
+	}
+	health.PermanentRedirects++
+	if health.PermanentRedirects < PermanentRedirectsBeforeMove {
+		return FetchOutcome{}
+	}
+
+	if !config.MoveFeed(feedURL, result.MovedTo) {
+		return FetchOutcome{}
+	}
+	state.MoveFeed(feedURL, result.MovedTo)
+	return FetchOutcome{MovedTo: result.MovedTo}
+}
+
+// MoveFeed changes the URL of a feed. If the new URL is already configured
+// the old entry is dropped instead. It reports whether the feed was found.
+func (c *Config) MoveFeed(oldURL, newURL string) bool {
+	index := -1
+	exists := false
+	for i, feedConfig := range c.Feeds {
+		switch feedConfig.URL {
+		case oldURL:
+			index = i
+		case newURL:
+			exists = true
+		}
+	}
+	if index < 0 {
+		return false
+	}
+
+	if exists {
+		c.Feeds = append(c.Feeds[:index], c.Feeds[index+1:]...)
+	} else {
+		c.Feeds[index].URL = newURL
+	}
+	return true
+}
+
+// DisableFeed stops a feed from being fetched, reporting whether it was
+// enabled before
+func (c *Config) DisableFeed(feedURL string) bool {
+	for i := range c.Feeds {
+		if c.Feeds[i].URL == feedURL && !c.Feeds[i].Disabled {
+			c.Feeds[i].Disabled = true
+			return true
+		}
+	}
+	return false
+}
+
+// MoveFeed carries the state of a feed over to its new URL. Read state is
+// kept per article, so it needs no changes.
+func (s *AppState) MoveFeed(oldURL, newURL string) {
+	if health, ok := s.FeedHealth[oldURL]; ok {
+		delete(s.FeedHealth, oldURL)
+		health.MovedTo = ""
+		health.PermanentRedirects = 0
+		s.FeedHealth[newURL] = health
+	}
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"bloom/internal/feed"
+	"testing"
+	"time"
+)
+
+func TestUpdateAfterFetchMovesFeed(t *testing.T) {
+	const oldURL, newURL = "https://old.example.com/feed", "https://new.example.com/feed"
+	config := &Config{Feeds: []FeedConfig{{URL: oldURL, Category: "News"}}}
+	state := NewAppState()
+	moved := feed.FetchResult{StatusCode: 200, MovedTo: newURL}
+	at := time.Now()
+
+	for i := 1; i < PermanentRedirectsBeforeMove; i++ {
+		if outcome := UpdateAfterFetch(config, state, oldURL, at, moved, nil); outcome.ConfigChanged() {
+			t.Fatalf("moved after %d redirects", i)
+		}
+	}
+
+	// A fetch that isn't redirected starts the count over
+	UpdateAfterFetch(config, state, oldURL, at, feed.FetchResult{StatusCode: 200}, nil)
+	for i := 1; i < PermanentRedirectsBeforeMove; i++ {
+		UpdateAfterFetch(config, state, oldURL, at, moved, nil)
+	}
+	if config.Feeds[0].URL != oldURL {
+		t.Fatal("redirect count wasn't reset")
+	}
+
+	outcome := UpdateAfterFetch(config, state, oldURL, at, moved, nil)
+	if outcome.MovedTo != newURL {
+		t.Fatalf("outcome = %+v, want a move", outcome)
+	}
+	if config.Feeds[0].URL != newURL || config.Feeds[0].Category != "News" {
+		t.Errorf("config feed = %+v", config.Feeds[0])
+	}
+	if state.Health(oldURL) != nil || state.Health(newURL) == nil {
+		t.Error("health wasn't carried over to the new URL")
+	}
+}
+
+func TestUpdateAfterFetchDisablesGoneFeed(t *testing.T) {
+	const url = "https://example.com/feed"
+	config := &Config{Feeds: []FeedConfig{{URL: url}}}
+	state := NewAppState()
+
+	outcome := UpdateAfterFetch(config, state, url, time.Now(), feed.FetchResult{StatusCode: 410}, &feed.HTTPError{StatusCode: 410})
+	if !outcome.Disabled || !config.Feeds[0].Disabled {
+		t.Errorf("outcome = %+v, feed = %+v", outcome, config.Feeds[0])
+	}
+
+	// Already disabled: nothing more to save
+	outcome = UpdateAfterFetch(config, state, url, time.Now(), feed.FetchResult{StatusCode: 410}, &feed.HTTPError{StatusCode: 410})
+	if outcome.ConfigChanged() {
+		t.Errorf("second 410 changed the config again")
+	}
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// opmlNamespace qualifies the bloom:tags attribute carrying FeedConfig.Tags
+const opmlNamespace = "https://github.com/zepzeper/bloom"
+
+type opmlDocument struct {
+	XMLName    xml.Name `xml:"opml"`
+	Version    string   `xml:"version,attr"`
+	XMLNSBloom string   `xml:"xmlns:bloom,attr,omitempty"`
+	Head       opmlHead `xml:"head"`
+	Body       opmlBody `xml:"body"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type opmlBody struct {
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Category string        `xml:"category,attr,omitempty"`
+	Tags     string        `xml:"bloom:tags,attr,omitempty"` // Written with its prefix; read back through Attrs
+	Attrs    []xml.Attr    `xml:",any,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// tags returns the outline's bloom:tags attribute
+func (o opmlOutline) tags() []string {
+	for _, attr := range o.Attrs {
+		if attr.Name.Local == "tags" && (attr.Name.Space == opmlNamespace || attr.Name.Space == "bloom") {
+			return splitTags(attr.Value)
+		}
+	}
+	return []string{}
+}
+
+// splitTags parses a comma-separated tag list
+func splitTags(s string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// ExportOPML writes the configured feeds as an OPML 2.0 document. Each
+// category becomes a folder outline, nested on "/" (e.g. "Tech/Go").
+func ExportOPML(config *Config, w io.Writer) error {
+	root := &opmlFolder{}
+	for _, feed := range config.Feeds {
+		folder := root
+		if path := categoryPath(feed.Category); path != "" {
+			for _, name := range strings.Split(path, "/") {
+				folder = folder.child(name)
+			}
+		}
+		text := feed.Title
+		if text == "" {
+			text = feed.URL
+		}
+		folder.feeds = append(folder.feeds, opmlOutline{
+			Text:   text,
+			Title:  feed.Title,
+			Type:   "rss",
+			XMLURL: feed.URL,
+			Tags:   strings.Join(feed.Tags, ","),
+		})
+	}
+
+	doc := opmlDocument{
+		Version:    "2.0",
+		XMLNSBloom: opmlNamespace,
+		Head: opmlHead{
+			Title:       "bloom subscriptions",
+			DateCreated: time.Now().Format(time.RFC1123Z),
+		},
+		Body: opmlBody{Outlines: root.outlines()},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write OPML: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// opmlFolder groups feeds by category while exporting
+type opmlFolder struct {
+	name    string
+	folders []*opmlFolder
+	feeds   []opmlOutline
+}
+
+func (f *opmlFolder) child(name string) *opmlFolder {
+	for _, folder := range f.folders {
+		if folder.name == name {
+			return folder
+		}
+	}
+	folder := &opmlFolder{name: name}
+	f.folders = append(f.folders, folder)
+	return folder
+}
+
+// outlines returns the folder's feeds followed by its subfolders
+func (f *opmlFolder) outlines() []opmlOutline {
+	outlines := append([]opmlOutline{}, f.feeds...)
+	for _, folder := range f.folders {
+		outlines = append(outlines, opmlOutline{
+			Text:     folder.name,
+			Title:    folder.name,
+			Outlines: folder.outlines(),
+		})
+	}
+	return outlines
+}
+
+// categoryPath normalizes a category for use as a folder path
+func categoryPath(category string) string {
+	return strings.Trim(strings.TrimSpace(category), "/")
+}
+
+// ImportOPML reads the feeds from an OPML document. Folder outlines become
+// the category of the feeds inside them, joined with "/" when nested.
+func ImportOPML(r io.Reader) ([]FeedConfig, error) {
+	var doc opmlDocument
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OPML: %v", err)
+	}
+
+	var feeds []FeedConfig
+	var walk func(outlines []opmlOutline, category string)
+	walk = func(outlines []opmlOutline, category string) {
+		for _, outline := range outlines {
+			if outline.XMLURL == "" {
+				// Folder
+				name := outline.Text
+				if name == "" {
+					name = outline.Title
+				}
+				child := name
+				if category != "" {
+					child = category + "/" + name
+				}
+				walk(outline.Outlines, child)
+				continue
+			}
+
+			feedCategory := category
+			if feedCategory == "" && outline.Category != "" {
+				// OPML 2.0 category attribute: comma-separated "/Path/Name" entries
+				feedCategory = categoryPath(strings.Split(outline.Category, ",")[0])
+			}
+
+			url := NormalizeFeedURL(outline.XMLURL)
+			title := strings.TrimSpace(outline.Title)
+			if title == "" {
+				title = strings.TrimSpace(outline.Text)
+			}
+			if title == outline.XMLURL || title == url {
+				// Readers without a title write the URL in its place
+				title = ""
+			}
+
+			feeds = append(feeds, FeedConfig{
+				URL:      url,
+				Title:    title,
+				Category: feedCategory,
+				Tags:     outline.tags(),
+			})
+		}
+	}
+	walk(doc.Body.Outlines, "")
+
+	return feeds, nil
+}
+
+// ImportOPMLFile reads the feeds from an OPML file
+func ImportOPMLFile(path string) ([]FeedConfig, error) {
+	file, err := os.Open(ExpandHome(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open OPML file: %v", err)
+	}
+	defer file.Close()
+
+	return ImportOPML(file)
+}
+
+// ExportOPMLFile writes the configured feeds to an OPML file
+func ExportOPMLFile(config *Config, path string) error {
+	path = ExpandHome(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create OPML file: %v", err)
+	}
+
+	if err := ExportOPML(config, file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOPMLRoundTrip(t *testing.T) {
+	config := &Config{Feeds: []FeedConfig{
+		{URL: "https://go.dev/blog/feed.atom", Title: "The Go Blog", Category: "Tech/Go", Tags: []string{"go", "official"}},
+		{URL: "https://example.com/rss", Category: "", Tags: []string{}},
+		{URL: "https://news.ycombinator.com/rss", Category: "Tech", Tags: []string{"news"}},
+		{URL: "https://blog.rust-lang.org/feed.xml", Category: "Tech/Rust", Tags: []string{}},
+	}}
+
+	var buf bytes.Buffer
+	if err := ExportOPML(config, &buf); err != nil {
+		t.Fatalf("ExportOPML: %v", err)
+	}
+	if !strings.Contains(buf.String(), `bloom:tags="go,official"`) {
+		t.Errorf("export missing bloom:tags attribute:\n%s", buf.String())
+	}
+
+	feeds, err := ImportOPML(&buf)
+	if err != nil {
+		t.Fatalf("ImportOPML: %v", err)
+	}
+
+	byURL := map[string]FeedConfig{}
+	for _, f := range feeds {
+		byURL[f.URL] = f
+	}
+	if len(byURL) != len(config.Feeds) {
+		t.Fatalf("imported %d feeds, want %d", len(byURL), len(config.Feeds))
+	}
+	for _, want := range config.Feeds {
+		got := byURL[want.URL]
+		if got.Title != want.Title || got.Category != want.Category || !reflect.DeepEqual(got.Tags, want.Tags) {
+			t.Errorf("%s: got title %q category %q tags %v, want %q %q %v", want.URL, got.Title, got.Category, got.Tags, want.Title, want.Category, want.Tags)
+		}
+	}
+}
+
+func TestImportOPMLFromOtherReaders(t *testing.T) {
+	const doc = `<?xml version="1.0"?>
+<opml version="1.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="News">
+      <outline text="LWN" type="rss" xmlUrl="https://lwn.net/headlines/rss"/>
+    </outline>
+    <outline text="Go" type="rss" xmlUrl="go.dev/blog/feed.atom" category="/Programming/Go,/Blogs"/>
+    <outline text="https://example.com/rss" type="rss" xmlUrl="https://example.com/rss"/>
+  </body>
+</opml>`
+
+	feeds, err := ImportOPML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("ImportOPML: %v", err)
+	}
+	want := []FeedConfig{
+		{URL: "https://lwn.net/headlines/rss", Title: "LWN", Category: "News", Tags: []string{}},
+		{URL: "https://go.dev/blog/feed.atom", Title: "Go", Category: "Programming/Go", Tags: []string{}},
+		{URL: "https://example.com/rss", Category: "", Tags: []string{}},
+	}
+	if !reflect.DeepEqual(feeds, want) {
+		t.Errorf("got %+v, want %+v", feeds, want)
+	}
+
+	config := &Config{Feeds: []FeedConfig{{URL: "https://lwn.net/headlines/rss"}}}
+	added := config.MergeFeeds(feeds)
+	if len(added) != 2 || added[0].URL != "https://go.dev/blog/feed.atom" {
+		t.Errorf("MergeFeeds added %+v, want the Go and example feeds", added)
+	}
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variables pointing bloom at other config and state files,
+// e.g. to keep separate profiles
+const (
+	ConfigEnv = "BLOOM_CONFIG"
+	StateEnv  = "BLOOM_STATE"
+)
+
+// Paths set with SetConfigPath and SetStatePath, taking precedence over the
+// environment
+var (
+	configPathOverride string
+	statePathOverride  string
+)
+
+// SetConfigPath makes bloom use the config file at path
+func SetConfigPath(path string) {
+	configPathOverride = ExpandHome(path)
+}
+
+// SetStatePath makes bloom use the state file at path
+func SetStatePath(path string) {
+	statePathOverride = ExpandHome(path)
+}
+
+// GetConfigPath returns the path to the config file: the one set with
+// SetConfigPath or $BLOOM_CONFIG, otherwise $XDG_CONFIG_HOME/bloom/config.json
+func GetConfigPath() (string, error) {
+	if path := overridePath(configPathOverride, ConfigEnv); path != "" {
+		return path, nil
+	}
+	dir, err := xdgDir("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// GetStatePath returns the path to the state file: the one set with
+// SetStatePath or $BLOOM_STATE, otherwise $XDG_STATE_HOME/bloom/state.json
+func GetStatePath() (string, error) {
+	if path := overridePath(statePathOverride, StateEnv); path != "" {
+		return path, nil
+	}
+	dir, err := xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state.json"), nil
+}
+
+// GetCacheDir returns the directory holding cached feed data,
+// $XDG_CACHE_HOME/bloom
+func GetCacheDir() (string, error) {
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+// overridePath returns the path set explicitly or in the environment
+func overridePath(override, env string) string {
+	if override != "" {
+		return override
+	}
+	return ExpandHome(os.Getenv(env))
+}
+
+// xdgDir returns bloom's directory under the XDG base directory in env, or
+// under fallback in the home directory when env is unset. Relative paths
+// are ignored, as the spec requires.
+func xdgDir(env, fallback string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "bloom"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, fallback, "bloom"), nil
+}
+
+// legacyPath returns where a file named name lived before bloom followed
+// the XDG spec, if path is the default location and only the old file
+// exists. Such files are read from there until they're next saved.
+// Explicitly set paths never fall back.
+func legacyPath(path, name string) (string, bool) {
+	override := overridePath(configPathOverride, ConfigEnv)
+	if name == "state.json" {
+		override = overridePath(statePathOverride, StateEnv)
+	}
+	if override != "" {
+		return "", false
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+	legacy := filepath.Join(homeDir, ".config", "bloom", name)
+	if legacy == path || !fileExists(legacy) || fileExists(path) {
+		return "", false
+	}
+	return legacy, true
+}
+
+// fileExists reports whether there's a file at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// ExpandHome replaces a leading "~" in path with the user's home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, path[1:])
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setTestHome points the home directory at a temporary one and clears the
+// variables that would take precedence over it
+func setTestHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME", ConfigEnv, StateEnv} {
+		t.Setenv(env, "")
+	}
+	return home
+}
+
+func TestPaths(t *testing.T) {
+	home := setTestHome(t)
+
+	paths := func() (string, string, string) {
+		t.Helper()
+		config, err := GetConfigPath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		state, err := GetStatePath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cache, err := GetCacheDir()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return config, state, cache
+	}
+	check := func(wantConfig, wantState, wantCache string) {
+		t.Helper()
+		config, state, cache := paths()
+		if config != wantConfig || state != wantState || cache != wantCache {
+			t.Errorf("got %s, %s, %s; want %s, %s, %s", config, state, cache, wantConfig, wantState, wantCache)
+		}
+	}
+
+	check(filepath.Join(home, ".config", "bloom", "config.json"),
+		filepath.Join(home, ".local", "state", "bloom", "state.json"),
+		filepath.Join(home, ".cache", "bloom"))
+
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+	t.Setenv("XDG_CACHE_HOME", "relative/is/ignored")
+	check("/xdg/config/bloom/config.json", "/xdg/state/bloom/state.json", filepath.Join(home, ".cache", "bloom"))
+
+	t.Setenv(ConfigEnv, "/profile/config.json")
+	t.Setenv(StateEnv, "~/profile/state.json")
+	check("/profile/config.json", filepath.Join(home, "profile", "state.json"), filepath.Join(home, ".cache", "bloom"))
+
+	SetConfigPath("/flag/config.json")
+	SetStatePath("/flag/state.json")
+	t.Cleanup(func() {
+		SetConfigPath("")
+		SetStatePath("")
+	})
+	check("/flag/config.json", "/flag/state.json", filepath.Join(home, ".cache", "bloom"))
+}
+
+func TestStateMovesFromLegacyLocation(t *testing.T) {
+	home := setTestHome(t)
+
+	legacy := filepath.Join(home, ".config", "bloom", "state.json")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte(`{"ReadArticles": {"a": true}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.IsRead("a") {
+		t.Fatal("expected the state to be read from the old location")
+	}
+	if err := SaveState(state); err != nil {
+		t.Fatal(err)
+	}
+
+	statePath, _ := GetStatePath()
+	if !fileExists(statePath) {
+		t.Errorf("expected the state to be saved to %s", statePath)
+	}
+}
+
+func TestExplicitPathIgnoresLegacyLocation(t *testing.T) {
+	home := setTestHome(t)
+
+	legacy := filepath.Join(home, ".config", "bloom", "state.json")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte(`{"ReadArticles": {"a": true}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(StateEnv, filepath.Join(home, "profile", "state.json"))
+
+	state, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.IsRead("a") {
+		t.Error("expected a new profile to start empty")
+	}
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"bloom/internal/feed"
+	"time"
+)
+
+// DefaultReadRetentionDays is used when the config doesn't set
+// ReadRetentionDays
+const DefaultReadRetentionDays = 90
+
+// ReadRetention returns how long read marks of items no longer in any feed
+// are kept, or 0 to keep them forever
+func (c *Config) ReadRetention() time.Duration {
+	days := c.ReadRetentionDays
+	switch {
+	case days < 0:
+		return 0
+	case days == 0:
+		days = DefaultReadRetentionDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// CompactReadMarks drops the read marks older than retention whose items
+// aren't in any of channels, and returns how many were dropped. A mark's
+// age is that of its item when the publication date is known, otherwise of
+// the mark. Marks from older states, which have neither, are dated now so
+// they expire in turn. Records of removed marks expire after retention too.
+//
+// No record is kept of the dropped marks; SaveState drops them from the
+// state on disk as well, so merging doesn't bring them back.
+func (s *AppState) CompactReadMarks(channels []*feed.Channel, retention time.Duration, now time.Time) int {
+	for key, mark := range s.ReadArticles {
+		if mark.Published.IsZero() && mark.ReadAt.IsZero() {
+			mark.ReadAt = now
+			s.ReadArticles[key] = mark
+		}
+	}
+	if retention <= 0 {
+		s.prune = nil
+		return 0
+	}
+
+	live := make(map[string]bool)
+	for _, channel := range channels {
+		for _, item := range channel.Item {
+			live[item.ID()] = true
+			if item.Link != "" {
+				live[item.Link] = true
+			}
+		}
+	}
+	s.prune = &readPrune{live: live, cutoff: now.Add(-retention), at: now}
+	return s.prune.apply(s)
+}
+
+// readPrune is how CompactReadMarks dropped read marks: those dated before
+// cutoff whose items weren't live at the time of the compaction
+type readPrune struct {
+	live   map[string]bool
+	cutoff time.Time
+	at     time.Time // Marks made since are kept, as their items may be new
+}
+
+// apply drops the read marks and the records of removed marks that are
+// past the cutoff, and returns how many read marks were dropped
+func (p *readPrune) apply(s *AppState) int {
+	dropped := 0
+	for key, mark := range s.ReadArticles {
+		date := mark.Published
+		if date.IsZero() {
+			date = mark.ReadAt
+		}
+		if !date.IsZero() && date.Before(p.cutoff) && !p.live[key] && !mark.ReadAt.After(p.at) {
+			delete(s.ReadArticles, key)
+			dropped++
+		}
+	}
+	for key, at := range s.Unread {
+		if at.Before(p.cutoff) {
+			delete(s.Unread, key)
+		}
+	}
+	return dropped
+}
+
+// CompactState applies the config's read retention to the state, taking
+// the feeds' items from the offline cache. Nothing is dropped while a feed
+// hasn't been cached yet, as its items can't be told apart from old ones.
+// It returns how many read marks were dropped.
+func CompactState(config *Config, state *AppState, cache *FeedCache, now time.Time) int {
+	retention := config.ReadRetention()
+
+	var channels []*feed.Channel
+	for _, feedConfig := range config.Feeds {
+		entry, ok := cache.Get(feedConfig.URL)
+		if ok && entry.Channel != nil {
+			channels = append(channels, entry.Channel)
+		} else if feedConfig.Enabled() {
+			retention = 0
+		}
+	}
+	return state.CompactReadMarks(channels, retention, now)
+}
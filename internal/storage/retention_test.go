@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"bloom/internal/feed"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestReadMarkAcceptsLegacyBool(t *testing.T) {
+	var state AppState
+	data := `{"ReadArticles": {"a": true, "b": {"ReadAt": "2024-01-02T00:00:00Z"}, "c": false}}`
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		t.Fatal(err)
+	}
+	if !state.IsRead("a") || !state.ReadArticles["a"].ReadAt.IsZero() {
+		t.Errorf("unexpected legacy mark: %+v", state.ReadArticles["a"])
+	}
+	if want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC); !state.ReadArticles["b"].ReadAt.Equal(want) {
+		t.Errorf("unexpected mark: %+v", state.ReadArticles["b"])
+	}
+	if state.IsRead("c") {
+		t.Error("expected a false entry not to be read")
+	}
+}
+
+func TestCompactReadMarks(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -100)
+	recent := now.AddDate(0, 0, -10)
+
+	state := NewAppState()
+	state.ReadArticles = map[string]ReadMark{
+		"old-gone":      {ReadAt: recent, Published: old}, // Dropped: published long ago
+		"old-live":      {ReadAt: old, Published: old},    // Kept: still in a feed
+		"read-long-ago": {ReadAt: old},                    // Dropped: no date, read long ago
+		"recent":        {ReadAt: recent},
+		"legacy":        {},
+	}
+	channel := &feed.Channel{Item: []feed.Item{{GUID: "old-live"}}}
+
+	if dropped := state.CompactReadMarks([]*feed.Channel{channel}, 90*24*time.Hour, now); dropped != 2 {
+		t.Errorf("expected 2 marks dropped, got %d", dropped)
+	}
+	for _, key := range []string{"old-live", "recent", "legacy"} {
+		if !state.IsRead(key) {
+			t.Errorf("expected %q to be kept", key)
+		}
+	}
+	if !state.ReadArticles["legacy"].ReadAt.Equal(now) {
+		t.Errorf("expected the legacy mark to be dated now: %+v", state.ReadArticles["legacy"])
+	}
+
+	// Keeping marks forever still dates legacy ones
+	if dropped := state.CompactReadMarks(nil, 0, now.AddDate(1, 0, 0)); dropped != 0 {
+		t.Errorf("expected nothing dropped without retention, got %d", dropped)
+	}
+}
+
+func TestReadRetention(t *testing.T) {
+	tests := []struct {
+		days int
+		want time.Duration
+	}{
+		{0, DefaultReadRetentionDays * 24 * time.Hour},
+		{30, 30 * 24 * time.Hour},
+		{-1, 0},
+	}
+	for _, tt := range tests {
+		config := Config{ReadRetentionDays: tt.days}
+		if got := config.ReadRetention(); got != tt.want {
+			t.Errorf("ReadRetention() with %d days = %v, want %v", tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestCompactedMarksLeaveTheStateFile(t *testing.T) {
+	setTestHome(t)
+	now := time.Now()
+	old := now.AddDate(0, 0, -100)
+
+	seed := NewAppState()
+	seed.ReadArticles["old"] = ReadMark{ReadAt: old, Published: old}
+	seed.ReadArticles["recent"] = ReadMark{ReadAt: now}
+	if err := SaveState(seed); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dropped := state.CompactReadMarks(nil, 90*24*time.Hour, now); dropped != 1 {
+		t.Fatalf("expected 1 mark dropped, got %d", dropped)
+	}
+	if err := SaveState(state); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.IsRead("old") || !saved.IsRead("recent") {
+		t.Errorf("expected only the recent mark: %v", saved.ReadArticles)
+	}
+	if len(saved.Unread) != 0 {
+		t.Errorf("expected no records of the dropped marks: %v", saved.Unread)
+	}
+}
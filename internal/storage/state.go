@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"bloom/internal/feed"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,28 +10,187 @@ import (
 )
 
 type AppState struct {
-	ReadArticles map[string]bool
+	ReadArticles map[string]ReadMark // Keyed by feed.Item.ID; older states by link
 	LastSync     time.Time
+	FeedHealth   map[string]*FeedHealth // Keyed by feed URL
+
+	// Unread records when read marks were removed, so merging with a state
+	// saved by another instance doesn't bring them back
+	Unread map[string]time.Time `json:",omitempty"`
+
+	prune *readPrune // The last compaction, applied again when saving
+}
+
+// ReadMark records when an item was read, and when it was published so
+// marks for old items can be pruned
+type ReadMark struct {
+	ReadAt    time.Time `json:",omitzero"`
+	Published time.Time `json:",omitzero"`
+
+	notRead bool // Decoded from a legacy false, see AppState.UnmarshalJSON
+}
+
+// UnmarshalJSON also accepts a bool, as older states stored read marks
+func (r *ReadMark) UnmarshalJSON(data []byte) error {
+	var read bool
+	if json.Unmarshal(data, &read) == nil {
+		*r = ReadMark{notRead: !read}
+		return nil
+	}
+	type plain ReadMark
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// UnmarshalJSON drops the read marks older states stored as false
+func (s *AppState) UnmarshalJSON(data []byte) error {
+	type plain AppState
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+	for key, mark := range s.ReadArticles {
+		if mark.notRead {
+			delete(s.ReadArticles, key)
+		}
+	}
+	return nil
 }
 
 func (s *AppState) MarkAsRead(articleURL string) {
-	s.ReadArticles[articleURL] = true
+	s.ReadArticles[articleURL] = ReadMark{ReadAt: time.Now()}
+	delete(s.Unread, articleURL)
 }
 
 func (s *AppState) IsRead(articleURL string) bool {
-	return s.ReadArticles[articleURL]
+	_, ok := s.ReadArticles[articleURL]
+	return ok
+}
+
+// IsItemRead reports whether an item has been read. Read marks stored by
+// link, as in states from before item IDs, are moved to the item's ID; the
+// link is recorded as unread so merging with the state on disk doesn't
+// bring the old mark back.
+func (s *AppState) IsItemRead(item feed.Item) bool {
+	id := item.ID()
+	if _, ok := s.ReadArticles[id]; ok {
+		return true
+	}
+	for _, link := range []string{item.Link, feed.NormalizeLink(item.Link)} {
+		mark, ok := s.ReadArticles[link]
+		if link == "" || !ok {
+			continue
+		}
+		if mark.Published.IsZero() {
+			mark.Published = item.Published
+		}
+		s.unmark(link, time.Now())
+		s.ReadArticles[id] = mark
+		return true
+	}
+	return false
+}
+
+// normalizeReadKeys rewrites read marks stored by link to the normalized
+// link, which is the ID of items without a guid
+func (s *AppState) normalizeReadKeys() {
+	now := time.Now()
+	for key, mark := range s.ReadArticles {
+		normalized := feed.NormalizeLink(key)
+		if normalized == key {
+			continue
+		}
+		s.unmark(key, now)
+		if existing, ok := s.ReadArticles[normalized]; !ok || existing.ReadAt.Before(mark.ReadAt) {
+			s.ReadArticles[normalized] = mark
+		}
+	}
+}
+
+// MarkItemRead marks an item as read
+func (s *AppState) MarkItemRead(item feed.Item) {
+	s.ReadArticles[item.ID()] = ReadMark{ReadAt: time.Now(), Published: item.Published}
+	delete(s.Unread, item.ID())
+}
+
+// MarkItemUnread marks an item as unread, including under its link as
+// stored by older states
+func (s *AppState) MarkItemUnread(item feed.Item) {
+	now := time.Now()
+	s.unmark(item.ID(), now)
+	if item.Link != "" {
+		s.unmark(item.Link, now)
+		s.unmark(feed.NormalizeLink(item.Link), now)
+	}
+}
+
+// unmark removes a read mark, recording when in Unread
+func (s *AppState) unmark(key string, at time.Time) {
+	delete(s.ReadArticles, key)
+	if s.Unread == nil {
+		s.Unread = make(map[string]time.Time)
+	}
+	s.Unread[key] = at
+}
+
+// MarkItems marks the items of channels given by link or ID as read or
+// unread, and returns how many changed. Keys matching no item are stored
+// as given, so a link is recognised once its item is fetched.
+func (s *AppState) MarkItems(channels []*feed.Channel, keys []string, read bool) int {
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	changed := 0
+	matched := make(map[string]bool)
+	for _, channel := range channels {
+		for _, item := range channel.Item {
+			id := item.ID()
+			if !wanted[id] && !wanted[item.Link] {
+				continue
+			}
+			matched[id] = true
+			matched[item.Link] = true
+			if s.IsItemRead(item) == read {
+				continue
+			}
+			if read {
+				s.MarkItemRead(item)
+			} else {
+				s.MarkItemUnread(item)
+			}
+			changed++
+		}
+	}
+
+	for _, key := range keys {
+		if matched[key] || s.IsRead(key) == read {
+			continue
+		}
+		if read {
+			s.MarkAsRead(key)
+		} else {
+			s.unmark(key, time.Now())
+		}
+		changed++
+	}
+	return changed
 }
 
 func LoadState() (*AppState, error) {
-	// Get config directory path
-	homeDir, err := os.UserHomeDir()
+	statePath, err := GetStatePath()
 	if err != nil {
 		return NewAppState(), nil // Return empty state if can't find home
 	}
+	if legacy, ok := legacyPath(statePath, "state.json"); ok {
+		statePath = legacy
+	}
 
-	configDir := filepath.Join(homeDir, ".config", "bloom")
-	statePath := filepath.Join(configDir, "state.json")
+	return loadStateFile(statePath)
+}
 
+// loadStateFile reads the state at path, or returns a new state if there's
+// no such file
+func loadStateFile(statePath string) (*AppState, error) {
 	// Check if file exists
 	if _, err := os.Stat(statePath); os.IsNotExist(err) {
 		// Return new state if file doesn't exist
@@ -51,36 +211,53 @@ func LoadState() (*AppState, error) {
 
 	// Initialize maps if nil
 	if state.ReadArticles == nil {
-		state.ReadArticles = make(map[string]bool)
+		state.ReadArticles = make(map[string]ReadMark)
+	}
+	if state.FeedHealth == nil {
+		state.FeedHealth = make(map[string]*FeedHealth)
 	}
+	state.normalizeReadKeys()
 
 	return &state, nil
 }
 
+// SaveState writes the state, merged with the state on disk so read marks
+// saved by another bloom instance in the meantime aren't lost
 func SaveState(state *AppState) error {
-	// Get config directory path
-	homeDir, err := os.UserHomeDir()
+	statePath, err := GetStatePath()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %v", err)
+		return fmt.Errorf("failed to get state path: %v", err)
 	}
 
-	configDir := filepath.Join(homeDir, ".config", "bloom")
-	statePath := filepath.Join(configDir, "state.json")
+	// Create state directory if it doesn't exist
+	err = os.MkdirAll(filepath.Dir(statePath), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create state directory: %v", err)
+	}
 
-	// Create config directory if it doesn't exist
-	err = os.MkdirAll(configDir, 0755)
+	unlock, err := lockFile(statePath)
 	if err != nil {
-		return fmt.Errorf("failed to create config directory: %v", err)
+		return err
+	}
+	defer unlock()
+
+	// A state that can't be read is replaced rather than merged
+	merged := state.clone()
+	if saved, err := loadStateFile(statePath); err == nil {
+		merged.merge(saved)
+	}
+	if state.prune != nil {
+		// The compacted marks are still on disk
+		state.prune.apply(merged)
 	}
 
-	// Marshal state to JSON
-	data, err := json.MarshalIndent(state, "", "  ")
+	// Marshal state to JSON, unindented as it holds every read mark
+	data, err := json.Marshal(merged)
 	if err != nil {
 		return fmt.Errorf("failed to marshal state: %v", err)
 	}
 
-	// Write to file
-	err = os.WriteFile(statePath, data, 0644)
+	err = writeFileAtomic(statePath, data, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write state file: %v", err)
 	}
@@ -91,8 +268,9 @@ func SaveState(state *AppState) error {
 // NewAppState creates a new empty AppState
 func NewAppState() *AppState {
 	return &AppState{
-		ReadArticles: make(map[string]bool),
+		ReadArticles: make(map[string]ReadMark),
 		LastSync:     time.Now(),
+		FeedHealth:   make(map[string]*FeedHealth),
 	}
 }
 
@@ -0,0 +1,73 @@
+package storage
+
+import "time"
+
+// clone returns a copy of the state that can be changed independently
+func (s *AppState) clone() *AppState {
+	c := &AppState{
+		ReadArticles: make(map[string]ReadMark, len(s.ReadArticles)),
+		LastSync:     s.LastSync,
+		FeedHealth:   make(map[string]*FeedHealth, len(s.FeedHealth)),
+		Unread:       make(map[string]time.Time, len(s.Unread)),
+	}
+	for key, mark := range s.ReadArticles {
+		c.ReadArticles[key] = mark
+	}
+	for url, health := range s.FeedHealth {
+		copied := *health
+		c.FeedHealth[url] = &copied
+	}
+	for key, at := range s.Unread {
+		c.Unread[key] = at
+	}
+	return c
+}
+
+// Clone returns a copy of the state to save while s goes on changing
+func (s *AppState) Clone() *AppState {
+	c := s.clone()
+	c.prune = s.prune
+	return c
+}
+
+// merge folds another instance's state into s. For each item the latest of
+// being read and being marked unread wins; for each feed, the health of the
+// latest fetch.
+func (s *AppState) merge(other *AppState) {
+	if s.Unread == nil {
+		s.Unread = make(map[string]time.Time)
+	}
+
+	for key, mark := range other.ReadArticles {
+		if mine, ok := s.ReadArticles[key]; ok {
+			if mark.ReadAt.After(mine.ReadAt) {
+				s.ReadArticles[key] = mark
+			}
+			continue
+		}
+		if at, ok := s.Unread[key]; ok && !mark.ReadAt.After(at) {
+			continue
+		}
+		s.ReadArticles[key] = mark
+		delete(s.Unread, key)
+	}
+
+	for key, at := range other.Unread {
+		if mine, ok := s.Unread[key]; ok && !at.After(mine) {
+			continue
+		}
+		if mark, ok := s.ReadArticles[key]; ok && mark.ReadAt.After(at) {
+			continue
+		}
+		s.unmark(key, at)
+	}
+
+	for url, health := range other.FeedHealth {
+		if mine, ok := s.FeedHealth[url]; !ok || health.LastAttempt.After(mine.LastAttempt) {
+			s.FeedHealth[url] = health
+		}
+	}
+	if other.LastSync.After(s.LastSync) {
+		s.LastSync = other.LastSync
+	}
+}
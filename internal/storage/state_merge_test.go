@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"bloom/internal/feed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSaveStateMergesInstances(t *testing.T) {
+	setTestHome(t)
+
+	seed := NewAppState()
+	seed.MarkAsRead("z")
+	if err := SaveState(seed); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two instances, say the TUI and a cron refresh, load the same state
+	tui, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cron, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cron.MarkAsRead("x")
+	if err := SaveState(cron); err != nil {
+		t.Fatal(err)
+	}
+
+	tui.MarkAsRead("y")
+	tui.MarkItemUnread(feed.Item{GUID: "z"})
+	if err := SaveState(tui); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cron instance still holds z as read, from before it was unmarked
+	if err := SaveState(cron); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.IsRead("x") || !saved.IsRead("y") {
+		t.Errorf("expected both instances' read marks: %v", saved.ReadArticles)
+	}
+	if saved.IsRead("z") {
+		t.Error("expected z to stay unread")
+	}
+}
+
+func TestSaveStateConcurrently(t *testing.T) {
+	home := setTestHome(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			state := NewAppState()
+			state.MarkAsRead(fmt.Sprint(i))
+			errs <- SaveState(state)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.ReadArticles) != 10 {
+		t.Errorf("expected 10 read marks, got %v", saved.ReadArticles)
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Join(home, ".local", "state", "bloom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if name := entry.Name(); name != "state.json" && name != "state.json.lock" {
+			t.Errorf("unexpected file %s", name)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"bloom/internal/feed"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadStateMigratesLinks(t *testing.T) {
+	home := setTestHome(t)
+
+	// A state from before item IDs, keyed by link
+	dir := filepath.Join(home, ".config", "bloom")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := `{"ReadArticles": {"http://example.com/a": true, "https://example.com/b": true, "http://example.com/c?utm_source=rss": true}}`
+	if err := os.WriteFile(filepath.Join(dir, "state.json"), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The link gained tracking parameters since it was read
+	a := feed.Item{Link: "https://example.com/a?utm_source=rss"}
+	if !state.IsItemRead(a) {
+		t.Error("expected the item read under its old link to be read")
+	}
+	// An item with a guid moves its read mark to the guid
+	b := feed.Item{GUID: "urn:b", Link: "https://example.com/b"}
+	if !state.IsItemRead(b) || !state.IsRead("urn:b") || state.IsRead("https://example.com/b") {
+		t.Errorf("expected the read mark to move to the guid: %v", state.ReadArticles)
+	}
+
+	state.MarkItemUnread(b)
+	if state.IsItemRead(b) {
+		t.Error("expected the item to be unread")
+	}
+
+	// Saving merges in the state on disk, which still has the old marks
+	c := feed.Item{GUID: "urn:c", Link: "http://example.com/c?utm_source=rss"}
+	state.MarkItemUnread(c)
+	if err := SaveState(state); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.IsItemRead(b) || saved.IsItemRead(c) {
+		t.Errorf("expected the items to stay unread after saving: %v", saved.ReadArticles)
+	}
+}
+
+func TestMarkItems(t *testing.T) {
+	state := NewAppState()
+	channel := &feed.Channel{Item: []feed.Item{
+		{GUID: "1", Link: "https://example.com/1"},
+		{GUID: "2", Link: "https://example.com/2"},
+	}}
+
+	if n := state.MarkItems([]*feed.Channel{channel}, []string{"https://example.com/1", "2", "https://example.com/3"}, true); n != 3 {
+		t.Errorf("expected 3 changes, got %d", n)
+	}
+	if !state.IsRead("1") || !state.IsRead("2") || !state.IsRead("https://example.com/3") {
+		t.Errorf("unexpected read marks: %v", state.ReadArticles)
+	}
+	if n := state.MarkItems([]*feed.Channel{channel}, []string{"1"}, true); n != 0 {
+		t.Errorf("expected no changes marking a read item, got %d", n)
+	}
+}
@@ -3,11 +3,13 @@ package tui
 import (
 	"bloom/internal/feed"
 	"bloom/internal/storage"
+	"context"
 	"fmt"
 	"net/url"
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -34,10 +36,19 @@ func normalizeFeedURL(rawURL string) string {
 	return rawURL
 }
 
-func LoadState() tea.Cmd {
+// LoadState loads the state and drops read marks past the retention of
+// config, which may be nil when it couldn't be loaded
+func LoadState(config *storage.Config, cache *storage.FeedCache) tea.Cmd {
 	return func() tea.Msg {
 		state, err := storage.LoadState()
-		return StateLoadMsg{State: state, Err: err}
+		if err != nil {
+			return StateLoadMsg{Err: err}
+		}
+		compacted := 0
+		if config != nil {
+			compacted = storage.CompactState(config, state, cache, time.Now())
+		}
+		return StateLoadMsg{State: state, Compacted: compacted}
 	}
 }
 
@@ -56,41 +67,120 @@ func LoadConfig() tea.Cmd {
 	}
 }
 
-// LoadFeedsFromConfig loads all feeds from the config
-func LoadFeedsFromConfig(config *storage.Config, reader *feed.Reader) tea.Cmd {
+// refreshTickInterval is how often feeds are checked for being due
+const refreshTickInterval = time.Minute
+
+// RefreshTick schedules the next check for feeds due for a refresh
+func RefreshTick() tea.Cmd {
+	return tea.Tick(refreshTickInterval, func(t time.Time) tea.Msg {
+		return RefreshTickMsg{Time: t}
+	})
+}
+
+// RefreshFeeds starts fetching the feeds through the scheduler. Progress
+// arrives through WaitForRefresh.
+func RefreshFeeds(scheduler *feed.Scheduler, urls []string) tea.Cmd {
 	return func() tea.Msg {
-		// Load all feeds in sequence
-		// In a real app, you might want to do this concurrently
-		var cmds []tea.Cmd
-		for _, feedConfig := range config.Feeds {
-			cmds = append(cmds, LoadFeed(feedConfig.URL))
+		ctx, cancel := context.WithCancel(context.Background())
+		return RefreshStartedMsg{
+			Total:   len(urls),
+			Updates: scheduler.Refresh(ctx, urls),
+			Cancel:  cancel,
 		}
-		
-		// For now, just return a message indicating we're done
-		// The feeds will be loaded via individual FeedLoadMsg messages
-		return FeedsLoadedMsg{Count: len(config.Feeds)}
+	}
+}
+
+// WaitForRefresh waits for the next progress update of a refresh
+func WaitForRefresh(updates <-chan feed.RefreshProgress) tea.Cmd {
+	return func() tea.Msg {
+		progress, ok := <-updates
+		if !ok {
+			return RefreshDoneMsg{Updates: updates}
+		}
+		return RefreshProgressMsg{Updates: updates, Progress: progress}
 	}
 }
 
 // LoadFeed loads an RSS feed from a URL
-func LoadFeed(rawURL string) tea.Cmd {
+func LoadFeed(reader *feed.Reader, rawURL string) tea.Cmd {
 	return func() tea.Msg {
 		normalizedURL := normalizeFeedURL(rawURL)
-		reader := feed.NewReader()
-		channel, err := reader.Read(normalizedURL)
-		if channel != nil {
+		result, err := reader.Fetch(context.Background(), normalizedURL)
+		if channel := result.Channel; channel != nil {
 			// Store the feed URL we used to fetch this channel
 			channel.FeedURL = normalizedURL
+			channel.FetchedAt = time.Now()
+			channel.Stale = false
 		}
-		return FeedLoadMsg{Channel: channel, Err: err}
+		return FeedLoadMsg{URL: normalizedURL, FetchResult: result, Err: err}
 	}
 }
 
-// LoadArticle loads an article from a URL
-func LoadArticle(fetcher *feed.ArticleFetcher, url string) tea.Cmd {
+// MoveCachedFeed keeps the offline copy of a feed that moved to a new URL
+func MoveCachedFeed(cache *storage.FeedCache, oldURL, newURL string) tea.Cmd {
 	return func() tea.Msg {
-		article, err := fetcher.Extract(url)
-		return ArticleLoadMsg{Article: article, Err: err}
+		// Losing the offline copy isn't worth reporting
+		cache.Move(oldURL, newURL)
+		return nil
+	}
+}
+
+// LoadCachedFeeds reads the last fetched copy of each configured feed from
+// the offline cache, so they can be shown before the network answers
+func LoadCachedFeeds(cache *storage.FeedCache, config *storage.Config) tea.Cmd {
+	return func() tea.Msg {
+		var channels []feed.Channel
+		for _, feedConfig := range config.Feeds {
+			normalizedURL := normalizeFeedURL(feedConfig.URL)
+			entry, ok := cache.Get(normalizedURL)
+			if !ok || entry.Channel == nil {
+				continue
+			}
+
+			channel := *entry.Channel
+			channel.FeedURL = normalizedURL
+			channel.FetchedAt = entry.FetchedAt
+			channel.Stale = true
+			channels = append(channels, channel)
+		}
+		return CachedFeedsLoadMsg{Channels: channels}
+	}
+}
+
+// LoadArticle loads the article an item of a feed links to
+func LoadArticle(fetcher *feed.ArticleFetcher, feedURL string, item feed.Item) tea.Cmd {
+	return func() tea.Msg {
+		article, err := fetcher.ExtractFromFeed(feedURL, item.Link)
+		return ArticleLoadMsg{ItemID: item.ID(), Article: article, Err: err}
+	}
+}
+
+// ShowItemContent shows the content the feed includes for an item as its
+// article
+func ShowItemContent(item feed.Item) tea.Cmd {
+	return func() tea.Msg {
+		article, err := item.Article()
+		return ArticleLoadMsg{ItemID: item.ID(), Article: article, Err: err}
+	}
+}
+
+// Notify shows a desktop notification. It's best effort: nothing is
+// reported when no notifier is available.
+func Notify(title, body string) tea.Cmd {
+	return func() tea.Msg {
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "linux":
+			cmd = exec.Command("notify-send", "--app-name=bloom", title, body)
+		case "darwin":
+			cmd = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", body, title))
+		default:
+			return nil
+		}
+		if err := cmd.Start(); err == nil {
+			go cmd.Wait()
+		}
+		return nil
 	}
 }
 
@@ -144,6 +234,15 @@ func AddFeedToConfig(config *storage.Config, feedConfig storage.FeedConfig) tea.
 	}
 }
 
+// DiscoverFeeds looks up the feeds behind the URL of a feed being added,
+// fetching with client when it isn't nil
+func DiscoverFeeds(reader *feed.Reader, client *feed.Client, feedConfig storage.FeedConfig) tea.Cmd {
+	return func() tea.Msg {
+		feeds, err := reader.DiscoverWith(client, normalizeFeedURL(feedConfig.URL))
+		return FeedsDiscoveredMsg{Feed: feedConfig, Feeds: feeds, Err: err}
+	}
+}
+
 // DeleteFeedFromConfig removes a feed from the configuration
 func DeleteFeedFromConfig(config *storage.Config, index int) tea.Cmd {
 	return func() tea.Msg {
@@ -171,6 +270,30 @@ func UpdateFeedInConfig(config *storage.Config, index int, feedConfig storage.Fe
 	}
 }
 
+// ImportOPML adds the feeds from an OPML file to the configuration
+func ImportOPML(config *storage.Config, path string) tea.Cmd {
+	return func() tea.Msg {
+		feeds, err := storage.ImportOPMLFile(path)
+		if err != nil {
+			return OPMLImportedMsg{Path: path, Err: err}
+		}
+
+		added := config.MergeFeeds(feeds)
+		if len(added) > 0 {
+			err = storage.SaveConfig(config)
+		}
+		return OPMLImportedMsg{Path: path, Added: added, Total: len(feeds), Err: err}
+	}
+}
+
+// ExportOPML writes the configured feeds to an OPML file
+func ExportOPML(config *storage.Config, path string) tea.Cmd {
+	return func() tea.Msg {
+		err := storage.ExportOPMLFile(config, path)
+		return OPMLExportedMsg{Path: path, Count: len(config.Feeds), Err: err}
+	}
+}
+
 // PasteFromClipboard reads content from the system clipboard
 func PasteFromClipboard() tea.Cmd {
 	return func() tea.Msg {
@@ -3,8 +3,10 @@ package components
 import (
 	"bloom/internal/feed"
 	"bloom/internal/tui/styles"
+	"bloom/internal/tui/utils"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // RenderArticleList renders the article list view
@@ -13,6 +15,8 @@ func RenderArticleList(currentFeed feed.Channel, cursor int, width int) string {
 		return styles.SubtleStyle().Render("No articles in this feed.")
 	}
 
+	now := time.Now()
+
 	var items []string
 	for i, item := range currentFeed.Item {
 		title := item.Title
@@ -26,25 +30,47 @@ func RenderArticleList(currentFeed feed.Channel, cursor int, width int) string {
 			indicator = "●" // Read
 		}
 
-		// Truncate long titles (account for indicator)
+		// Age of the item, shown after the title
+		age := utils.RelativeTime(item.Published, now)
+
+		// Truncate long titles (account for indicator and age)
 		maxTitleWidth := width - 6
-		if len(title) > maxTitleWidth {
+		if age != "" {
+			maxTitleWidth -= len(age) + 2
+		}
+		if len(title) > maxTitleWidth && maxTitleWidth > 3 {
 			title = title[:maxTitleWidth-3] + "..."
 		}
 
 		if cursor == i {
 			// Selected item - reverse video
 			itemText := styles.SelectedStyle().Render(fmt.Sprintf("%s > %s", indicator, title))
+			if age != "" {
+				itemText += "  " + styles.DateStyle().Render(age)
+			}
 			items = append(items, itemText)
 
-			// Show date for selected item
-			if item.PubDate != "" {
-				dateLine := styles.DateStyle().Render("    " + item.PubDate)
+			// Show full date and author for selected item
+			meta := item.PubDate
+			if !item.Published.IsZero() {
+				meta = item.Published.Local().Format("Mon, 02 Jan 2006 15:04")
+			}
+			if item.Author != "" {
+				if meta != "" {
+					meta += " · "
+				}
+				meta += item.Author
+			}
+			if meta != "" {
+				dateLine := styles.DateStyle().Render("    " + meta)
 				items = append(items, dateLine)
 			}
 		} else {
 			// Normal item - plain text
 			itemText := styles.NormalStyle().Render(fmt.Sprintf("%s   %s", indicator, title))
+			if age != "" {
+				itemText += "  " + styles.DateStyle().Render(age)
+			}
 			items = append(items, itemText)
 		}
 	}
@@ -0,0 +1,115 @@
+package components
+
+import (
+	"bloom/internal/storage"
+	"strconv"
+	"strings"
+)
+
+// FeedFormField is a field of the feed edit form
+type FeedFormField struct {
+	Key     string
+	Label   string
+	Empty   string   // Shown when the value is empty
+	Choices []string // The values space cycles through; nil for typed fields
+}
+
+// FeedFormFields are the fields of the feed edit form, in tab order
+var FeedFormFields = []FeedFormField{
+	{Key: "url", Label: "URL"},
+	{Key: "title", Label: "Title", Empty: "(feed's own)"},
+	{Key: "category", Label: "Category", Empty: "(empty)"},
+	{Key: "tags", Label: "Tags", Empty: "(empty)"},
+	{Key: "paused", Label: "Paused", Choices: []string{"no", "yes"}},
+	{Key: "refresh", Label: "Refresh every (min)", Empty: "(default)"},
+	{Key: "max_items", Label: "Max items", Empty: "(all)"},
+	{Key: "open", Label: "Open items with", Choices: storage.OpenActions},
+	{Key: "mark_read", Label: "Mark read on view", Choices: []string{"default", "yes", "no"}},
+	{Key: "notify", Label: "Notify on new items", Choices: []string{"no", "yes"}},
+}
+
+// FindFeedFormField returns the index of the form field with the given key,
+// or -1
+func FindFeedFormField(key string) int {
+	for i, field := range FeedFormFields {
+		if field.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// FeedFieldValue formats a setting of a feed as it's edited in the form
+func FeedFieldValue(feed storage.FeedConfig, key string) string {
+	switch key {
+	case "url":
+		return feed.URL
+	case "title":
+		return feed.Title
+	case "category":
+		return feed.Category
+	case "tags":
+		return strings.Join(feed.Tags, ", ")
+	case "paused":
+		return yesNo(feed.Paused)
+	case "refresh":
+		if feed.RefreshIntervalMin == 0 {
+			return ""
+		}
+		return strconv.Itoa(feed.RefreshIntervalMin)
+	case "max_items":
+		if feed.MaxItems == 0 {
+			return ""
+		}
+		return strconv.Itoa(feed.MaxItems)
+	case "open":
+		return feed.Open()
+	case "mark_read":
+		if feed.MarkReadOnView == nil {
+			return "default"
+		}
+		return yesNo(*feed.MarkReadOnView)
+	case "notify":
+		return yesNo(feed.Notify)
+	}
+	return ""
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// feedSettings summarizes the settings of a feed that differ from the
+// defaults, such as "paused, every 30 min"
+func feedSettings(feed storage.FeedConfig) string {
+	var settings []string
+	if feed.Paused {
+		settings = append(settings, "paused")
+	}
+	if feed.RefreshIntervalMin > 0 {
+		settings = append(settings, "every "+strconv.Itoa(feed.RefreshIntervalMin)+" min")
+	}
+	if feed.MaxItems > 0 {
+		settings = append(settings, "keeps "+strconv.Itoa(feed.MaxItems)+" items")
+	}
+	switch feed.Open() {
+	case storage.OpenContent:
+		settings = append(settings, "opens feed content")
+	case storage.OpenBrowser:
+		settings = append(settings, "opens in browser")
+	}
+	if feed.MarkReadOnView != nil {
+		if *feed.MarkReadOnView {
+			settings = append(settings, "marks read on view")
+		} else {
+			settings = append(settings, "doesn't mark read on view")
+		}
+	}
+	if feed.Notify {
+		settings = append(settings, "notifies")
+	}
+	return strings.Join(settings, ", ")
+}
@@ -7,9 +7,11 @@ import (
 	"bloom/internal/tui/utils"
 	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
-func RenderFeedList(configFeeds []storage.FeedConfig, loadedFeeds []feed.Channel, currentFeed int, width int) string {
+func RenderFeedList(configFeeds []storage.FeedConfig, loadedFeeds []feed.Channel, health map[string]*storage.FeedHealth, newItems map[string]int, currentFeed int, width int) string {
 	if len(configFeeds) == 0 {
 		return styles.SubtleStyle().Render("No feeds configured. Press 'm' to manage feeds.")
 	}
@@ -28,43 +30,86 @@ func RenderFeedList(configFeeds []storage.FeedConfig, loadedFeeds []feed.Channel
 		var title string
 		var description string
 		var isLoaded bool
+		feedHealth := health[feedConfig.URL]
+		failing := feedHealth != nil && feedHealth.Failing() && feedConfig.Enabled()
 
 		if loadedFeed != nil {
 			// Feed is loaded
 			isLoaded = true
-			title = loadedFeed.Title
+			title = feedConfig.DisplayTitle(loadedFeed.Title)
 			if title == "" {
 				title = "(Untitled)"
 			}
 			description = loadedFeed.Description
+			if loadedFeed.Recovered {
+				// Feed was malformed and only parsed after repair
+				title = title + " (repaired)"
+			}
+			if n := newItems[loadedFeed.FeedURL]; n > 0 {
+				title = fmt.Sprintf("%s (%d new)", title, n)
+			}
+			if feedConfig.Disabled {
+				title = title + " (disabled)"
+			} else if feedConfig.Paused {
+				title = title + " (paused)"
+			} else if loadedFeed.Stale {
+				// Only the offline copy is available so far
+				title = title + " (cached)"
+			}
 		} else {
 			// Feed not loaded yet or failed to load
 			isLoaded = false
 			// Use URL as title if feed hasn't loaded
-			title = feedConfig.URL
-			if len(title) > width-20 {
-				title = title[:width-23] + "..."
+			title = feedConfig.DisplayTitle(feedConfig.URL)
+			title = truncate(title, width-20)
+			switch {
+			case feedConfig.Disabled:
+				title = title + " (disabled)"
+			case feedConfig.Paused:
+				title = title + " (paused)"
+			case failing:
+				title = title + " (failed)"
+			default:
+				title = title + " (Loading...)"
 			}
-			title = title + " (Loading...)"
 		}
 
 		if currentFeed == i {
 			// Selected item
-			item := styles.SelectedStyle().Render("> " + title)
+			item := styles.SelectedStyle().Render("> " + healthMarker(feedConfig, feedHealth) + " " + title)
 			items = append(items, item)
 
 			// Show description for selected item if loaded
 			if isLoaded && description != "" {
 				desc := utils.StripHTML(description)
-				if len(desc) > width-4 {
-					desc = desc[:width-7] + "..."
-				}
+				desc = truncate(desc, width-4)
 				descLine := styles.DescriptionStyle().Render("  " + desc)
 				items = append(items, descLine)
 			}
+
+			// Explain why the feed needed repair
+			if isLoaded && loadedFeed.Recovered {
+				note := truncate("Malformed feed: "+loadedFeed.RecoveryNote, width-4)
+				items = append(items, styles.SubtleStyle().Render("  "+note))
+			}
+
+			// Say why the feed is failing or no longer fetched
+			if feedConfig.Disabled {
+				items = append(items, styles.ErrorStyle().Render("  The feed is gone (410); edit its URL in the feed manager to enable it again"))
+			} else if feedConfig.Paused {
+				items = append(items, styles.SubtleStyle().Render("  Paused; resume it in the feed manager"))
+			} else if failing {
+				items = append(items, styles.ErrorStyle().Render("  "+truncate(describeFailure(feedHealth), width-4)))
+			}
+
+			// Say how old the offline copy is
+			if isLoaded && loadedFeed.Stale && !loadedFeed.FetchedAt.IsZero() {
+				age := utils.RelativeTime(loadedFeed.FetchedAt, time.Now())
+				items = append(items, styles.SubtleStyle().Render("  Offline copy, fetched "+age))
+			}
 		} else {
 			// Normal item
-			item := styles.NormalStyle().Render("  " + title)
+			item := styles.NormalStyle().Render("  " + healthMarker(feedConfig, feedHealth) + " " + title)
 			items = append(items, item)
 		}
 	}
@@ -72,11 +117,55 @@ func RenderFeedList(configFeeds []storage.FeedConfig, loadedFeeds []feed.Channel
 	return strings.Join(items, "\n")
 }
 
-func RenderFeedStatusBar(feedCount int, width int) string {
+// healthMarker is the health column of the feed list: ✓ when the last
+// fetch succeeded, ✗ when it failed, - for disabled or paused feeds and
+// blank before the first attempt
+func healthMarker(feedConfig storage.FeedConfig, health *storage.FeedHealth) string {
+	switch {
+	case !feedConfig.Enabled():
+		return "-"
+	case health == nil:
+		return " "
+	case health.Failing():
+		return "✗"
+	default:
+		return "✓"
+	}
+}
+
+// describeFailure summarizes why a feed is failing
+func describeFailure(health *storage.FeedHealth) string {
+	s := health.LastError
+	if health.ConsecutiveFailures > 1 {
+		s += fmt.Sprintf(" (%d failures in a row)", health.ConsecutiveFailures)
+	}
+	return s
+}
+
+// truncate shortens s to at most width bytes, ending in "..." and without
+// splitting a character
+func truncate(s string, width int) string {
+	if width < 4 || len(s) <= width {
+		return s
+	}
+	cut := width - 3
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
+
+// RenderFeedStatusBar renders the status bar for the feed list. A non-empty
+// refresh status replaces the feed count.
+func RenderFeedStatusBar(feedCount int, refresh string, width int) string {
+	center := fmt.Sprintf("%d feed(s)", feedCount)
+	if refresh != "" {
+		center = refresh
+	}
 	return styles.RenderStatusBar(
 		"Feeds",
-		fmt.Sprintf("%d feed(s)", feedCount),
-		"↑↓: Navigate  Enter: Open  f: Manage  Esc: Home  q: Quit",
+		center,
+		"↑↓: Navigate  Enter: Open  f: Manage  h: Health  Esc: Home  q: Quit",
 		width,
 	)
 }
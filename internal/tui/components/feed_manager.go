@@ -1,6 +1,7 @@
 package components
 
 import (
+	"bloom/internal/feed"
 	"bloom/internal/storage"
 	"bloom/internal/tui/styles"
 	"fmt"
@@ -10,9 +11,9 @@ import (
 )
 
 // RenderFeedManager renders the feed management view
-func RenderFeedManager(feeds []storage.FeedConfig, cursor int, editing bool, editField string, editValue string, width int) string {
+func RenderFeedManager(feeds []storage.FeedConfig, cursor int, editing bool, editField string, editValue string, editErr string, width int) string {
 	if len(feeds) == 0 {
-		return styles.SubtleStyle().Render("No feeds configured. Press 'a' to add a feed.")
+		return styles.SubtleStyle().Render("No feeds configured. Press 'a' to add a feed or 'i' to import OPML.")
 	}
 
 	var items []string
@@ -28,7 +29,7 @@ func RenderFeedManager(feeds []storage.FeedConfig, cursor int, editing bool, edi
 		var feedDisplay string
 		if isEditing {
 			// Show edit form
-			feedDisplay = renderEditForm(feed, editField, editValue, width)
+			feedDisplay = renderEditForm(feed, editField, editValue, editErr, width)
 		} else {
 			// Show normal feed info
 			feedDisplay = renderFeedInfo(feed, cursor == i, width)
@@ -47,6 +48,9 @@ func RenderFeedManager(feeds []storage.FeedConfig, cursor int, editing bool, edi
 
 func renderFeedInfo(feed storage.FeedConfig, selected bool, width int) string {
 	url := feed.URL
+	if feed.Title != "" {
+		url = feed.Title + " - " + url
+	}
 	if len(url) > width-10 {
 		url = url[:width-13] + "..."
 	}
@@ -61,74 +65,103 @@ func renderFeedInfo(feed storage.FeedConfig, selected bool, width int) string {
 		tags = "No tags"
 	}
 
+	settings := feedSettings(feed)
+
 	var lines []string
 	if selected {
 		lines = append(lines, styles.SelectedStyle().Render(fmt.Sprintf("> %s", url)))
 		lines = append(lines, styles.SubtleStyle().Render(fmt.Sprintf("  Category: %s", category)))
 		lines = append(lines, styles.SubtleStyle().Render(fmt.Sprintf("  Tags: %s", tags)))
+		if settings != "" {
+			lines = append(lines, styles.SubtleStyle().Render(fmt.Sprintf("  Settings: %s", settings)))
+		}
 	} else {
+		line := fmt.Sprintf("  Category: %s | Tags: %s", category, tags)
+		if settings != "" {
+			line += " | " + settings
+		}
 		lines = append(lines, styles.NormalStyle().Render(fmt.Sprintf("  %s", url)))
-		lines = append(lines, styles.SubtleStyle().Render(fmt.Sprintf("  Category: %s | Tags: %s", category, tags)))
+		lines = append(lines, styles.SubtleStyle().Render(line))
 	}
 
 	return strings.Join(lines, "\n")
 }
 
-func renderEditForm(feed storage.FeedConfig, editField string, editValue string, width int) string {
+func renderEditForm(feed storage.FeedConfig, editField string, editValue string, editErr string, width int) string {
 	var lines []string
 
-	// URL field
-	urlLabel := "URL: "
-	urlValue := feed.URL
-	if editField == "url" {
-		urlValue = editValue + "█" // Show cursor
-		urlLabel = "> " + urlLabel
-	}
-	lines = append(lines, styles.ArticleTitleStyle().Render(urlLabel)+urlValue)
+	for _, field := range FeedFormFields {
+		label := field.Label + ": "
+		value := FeedFieldValue(feed, field.Key)
+		if value == "" {
+			value = field.Empty
+		}
+		if editField == field.Key {
+			label = "> " + label
+			if field.Choices != nil {
+				value = "‹ " + editValue + " ›"
+			} else {
+				value = editValue + "█" // Show cursor
+			}
+		}
 
-	// Category field
-	categoryLabel := "Category: "
-	categoryValue := feed.Category
-	if categoryValue == "" {
-		categoryValue = "(empty)"
-	}
-	if editField == "category" {
-		categoryValue = editValue + "█"
-		categoryLabel = "> " + categoryLabel
+		style := styles.NormalStyle()
+		if field.Key == "url" {
+			style = styles.ArticleTitleStyle()
+		}
+		lines = append(lines, style.Render(label)+value)
 	}
-	lines = append(lines, styles.NormalStyle().Render(categoryLabel)+categoryValue)
 
-	// Tags field
-	tagsLabel := "Tags: "
-	tagsValue := strings.Join(feed.Tags, ", ")
-	if tagsValue == "" {
-		tagsValue = "(empty)"
+	// Why the current field was rejected
+	if editErr != "" {
+		lines = append(lines, "", styles.ErrorStyle().Render(truncate(editErr, width-2)))
 	}
-	if editField == "tags" {
-		tagsValue = editValue + "█"
-		tagsLabel = "> " + tagsLabel
-	}
-	lines = append(lines, styles.NormalStyle().Render(tagsLabel)+tagsValue)
 
 	// Instructions
 	lines = append(lines, "")
-	lines = append(lines, styles.SubtleStyle().Render("Tab: Next field | Ctrl+V: Paste | Enter: Save | Esc: Cancel"))
+	lines = append(lines, styles.SubtleStyle().Render("Tab/Shift+Tab: Next/previous field | Space: Change choice | Ctrl+V: Paste | Enter: Save | Esc: Cancel"))
 
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
-// RenderFeedManagerStatusBar renders the status bar for feed management view
-func RenderFeedManagerStatusBar(feedCount int, width int) string {
+// RenderFeedManagerStatusBar renders the status bar for feed management view.
+// A non-empty notice replaces the feed count.
+func RenderFeedManagerStatusBar(feedCount int, notice string, width int) string {
+	center := fmt.Sprintf("%d feeds", feedCount)
+	if notice != "" {
+		center = notice
+	}
 	return styles.RenderStatusBar(
 		"Feed Manager",
-		fmt.Sprintf("%d feeds", feedCount),
-		"a: Add  e: Edit  d: Delete  r: Reload  Esc: Home  q: Quit",
+		center,
+		"a: Add  e: Edit  d: Delete  r: Reload  i: Import  x: Export  Esc: Home  q: Quit",
 		width,
 	)
 }
 
+// RenderOPMLPrompt renders the prompt for an OPML file to import or export
+func RenderOPMLPrompt(action string, path string, width int) string {
+	var lines []string
+
+	// Title
+	title := "Import OPML"
+	help := "Feeds already configured are skipped."
+	if action == "export" {
+		title = "Export OPML"
+		help = "The file is overwritten if it exists."
+	}
+	lines = append(lines, styles.ArticleTitleStyle().Render(title), "")
+
+	lines = append(lines, styles.NormalStyle().Render("> File: ")+path+"█")
+	lines = append(lines, "")
+	lines = append(lines, styles.SubtleStyle().Render(help))
+	lines = append(lines, styles.SubtleStyle().Render("Ctrl+V: Paste | Enter: Confirm | Esc: Cancel"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
 // RenderAddFeedForm renders the form for adding a new feed
-func RenderAddFeedForm(url, category, tags string, currentField string, width int) string {
+func RenderAddFeedForm(url, category, tags string, currentField string, errMsg string, width int) string {
 	var lines []string
 
 	// Title
@@ -171,10 +204,43 @@ func RenderAddFeedForm(url, category, tags string, currentField string, width in
 	}
 	lines = append(lines, styles.NormalStyle().Render(tagsLabel)+tagsValue)
 
+	// Why the last attempt failed
+	if errMsg != "" {
+		lines = append(lines, "", styles.ErrorStyle().Render(truncate(errMsg, width-2)))
+	}
+
 	// Instructions
 	lines = append(lines, "")
-	lines = append(lines, styles.SubtleStyle().Render("Tab: Next field | Ctrl+V: Paste | Enter: Save | Esc: Cancel"))
+	lines = append(lines, styles.SubtleStyle().Render("Tab: Next field | Ctrl+V: Paste | Enter: Find feed and save | Ctrl+S: Save URL as entered | Esc: Cancel"))
 
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
+// RenderFeedPicker renders the list of feeds discovered on a page
+func RenderFeedPicker(feeds []feed.DiscoveredFeed, cursor int, width int) string {
+	var lines []string
+
+	// Title
+	title := styles.ArticleTitleStyle().Render("Choose a Feed")
+	lines = append(lines, title, "")
+	lines = append(lines, styles.SubtleStyle().Render("This page offers several feeds:"), "")
+
+	textWidth := max(width-6, 4)
+	for i, found := range feeds {
+		name := found.Title
+		if name == "" {
+			name = found.URL
+		}
+		name = truncate(name, textWidth)
+		url := truncate(found.URL, textWidth)
+
+		if cursor == i {
+			lines = append(lines, styles.SelectedStyle().Render("> "+name))
+		} else {
+			lines = append(lines, styles.NormalStyle().Render("  "+name))
+		}
+		lines = append(lines, styles.SubtleStyle().Render(fmt.Sprintf("    %s  %s", url, found.Type)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,71 @@
+package components
+
+import (
+	"bloom/internal/storage"
+	"bloom/internal/tui/styles"
+	"bloom/internal/tui/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RenderFeedHealth renders the feed status view: every failing feed with
+// its last error, followed by a count of the healthy ones
+func RenderFeedHealth(configFeeds []storage.FeedConfig, health map[string]*storage.FeedHealth, width int) string {
+	var lines []string
+
+	title := styles.ArticleTitleStyle().Render("Feed Health")
+	lines = append(lines, title, "")
+
+	now := time.Now()
+	healthy, pending := 0, 0
+	for _, feedConfig := range configFeeds {
+		feedHealth := health[feedConfig.URL]
+		if feedHealth == nil {
+			pending++
+			continue
+		}
+		if !feedHealth.Failing() {
+			healthy++
+			continue
+		}
+
+		lines = append(lines, styles.ErrorStyle().Render("✗ "+truncate(feedConfig.URL, width-2)))
+		lines = append(lines, styles.NormalStyle().Render("  "+truncate(describeFailure(feedHealth), width-2)))
+
+		var details []string
+		if feedHealth.StatusCode != 0 {
+			details = append(details, fmt.Sprintf("HTTP %d", feedHealth.StatusCode))
+		}
+		details = append(details, "last tried "+utils.RelativeTime(feedHealth.LastAttempt, now))
+		if feedHealth.LastSuccess.IsZero() {
+			details = append(details, "never succeeded")
+		} else {
+			details = append(details, "last worked "+utils.RelativeTime(feedHealth.LastSuccess, now))
+		}
+		lines = append(lines, styles.SubtleStyle().Render("  "+strings.Join(details, " · ")), "")
+	}
+
+	if healthy+pending == len(configFeeds) {
+		lines = append(lines, styles.NormalStyle().Render("No feeds are failing."), "")
+	}
+	summary := fmt.Sprintf("%d healthy", healthy)
+	if pending > 0 {
+		summary += fmt.Sprintf(", %d not fetched yet", pending)
+	}
+	lines = append(lines, styles.SubtleStyle().Render(summary))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// RenderFeedHealthStatusBar renders the status bar for the feed status view
+func RenderFeedHealthStatusBar(failing int, width int) string {
+	return styles.RenderStatusBar(
+		"Feed Health",
+		fmt.Sprintf("%d failing", failing),
+		"Esc: Back  q: Quit",
+		width,
+	)
+}
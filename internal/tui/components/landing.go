@@ -150,11 +150,11 @@ func min(a, b int) int {
 }
 
 // RenderLandingStatusBar renders the status bar for the landing page
-func RenderLandingStatusBar(width int) string {
+func RenderLandingStatusBar(refresh string, width int) string {
 	return styles.RenderStatusBar(
 		"Welcome",
-		"",
-		"f: Feeds  m: Manage  s: Save  q: Quit",
+		refresh,
+		"f: Feeds  m: Manage  h: Health  s: Save  q: Quit",
 		width,
 	)
 }
@@ -139,7 +139,7 @@ func HandleEnter(m *tui.Model) (*tui.Model, tea.Cmd) {
 					if m.Cursor < len(feed.Item) {
 						item := feed.Item[m.Cursor]
 						m.Loading = true
-						return m, tui.LoadArticle(m.Fetcher, item.Link)
+						return m, tui.LoadArticle(m.Fetcher, feed.FeedURL, item)
 					}
 					break
 				}
@@ -4,8 +4,9 @@ import tea "github.com/charmbracelet/bubbletea"
 
 // Init initializes the model (bubbletea interface)
 func (m Model) Init() tea.Cmd {
+	// The state is loaded once the config is, to apply its read retention
 	return tea.Batch(
-		LoadState(),
 		LoadConfig(),
+		RefreshTick(),
 	)
 }
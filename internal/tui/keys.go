@@ -3,8 +3,11 @@ package tui
 import (
 	"bloom/internal/feed"
 	"bloom/internal/storage"
+	"bloom/internal/tui/components"
 	"bloom/internal/tui/utils"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -124,6 +127,13 @@ func handleKeyMsg(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 			return toggleReadStatus(m)
 		}
 		return m, nil
+	case "h":
+		// Show feed health (from landing or feed view)
+		if m.CurrentView == "landing" || m.CurrentView == "feed" {
+			m.CurrentView = "health"
+			return m, nil
+		}
+		return m, nil
 	case "s":
 		// Save state manually
 		if m.State != nil {
@@ -198,6 +208,8 @@ func handleEnter(m *Model) (*Model, tea.Cmd) {
 					if len(m.Feeds[i].Item) > 0 {
 						m.CurrentView = "articles"
 						m.Cursor = 0
+						// The new items have been seen
+						delete(m.NewItems, m.Feeds[i].FeedURL)
 					}
 					break
 				}
@@ -213,8 +225,21 @@ func handleEnter(m *Model) (*Model, tea.Cmd) {
 					feed := m.Feeds[i]
 					if m.Cursor < len(feed.Item) {
 						item := feed.Item[m.Cursor]
+						open := m.Config.Feeds[m.CurrentFeed].Open()
+						if item.Link == "" {
+							// Only the feed's content can be shown
+							open = storage.OpenContent
+						}
+						switch open {
+						case storage.OpenBrowser:
+							markViewed(m, item.ID())
+							return m, tea.Batch(OpenLink(item.Link), saveStateIfLoaded(m))
+						case storage.OpenContent:
+							m.Loading = true
+							return m, ShowItemContent(item)
+						}
 						m.Loading = true
-						return m, LoadArticle(m.Fetcher, item.Link)
+						return m, LoadArticle(m.Fetcher, feed.FeedURL, item)
 					}
 					break
 				}
@@ -246,6 +271,8 @@ func handleEscape(m *Model) (*Model, tea.Cmd) {
 	case "manage":
 		m.CurrentView = "landing"
 		m.Cursor = 0
+	case "health":
+		m.CurrentView = "feed"
 	}
 	return m, nil
 }
@@ -282,10 +309,9 @@ func toggleReadStatus(m *Model) (*Model, tea.Cmd) {
 	item.Read = !item.Read
 
 	if item.Read {
-		m.State.MarkAsRead(item.Link)
+		m.State.MarkItemRead(*item)
 	} else {
-		// Remove from read articles
-		delete(m.State.ReadArticles, item.Link)
+		m.State.MarkItemUnread(*item)
 	}
 
 	return m, SaveState(m.State)
@@ -293,6 +319,11 @@ func toggleReadStatus(m *Model) (*Model, tea.Cmd) {
 
 // handleFeedManagementKeys handles keyboard input in feed management view
 func handleFeedManagementKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
+	// Handle choosing between discovered feeds
+	if m.PickingFeed {
+		return handleFeedPickerKeys(m, msg)
+	}
+
 	// Handle adding a new feed
 	if m.AddingFeed {
 		return handleAddFeedKeys(m, msg)
@@ -303,6 +334,13 @@ func handleFeedManagementKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 		return handleEditFeedKeys(m, msg)
 	}
 
+	// Handle the OPML path prompt
+	if m.OPMLAction != "" {
+		return handleOPMLPathKeys(m, msg)
+	}
+
+	m.Notice = ""
+
 	// Normal feed management navigation
 	switch msg.String() {
 	case "esc":
@@ -326,6 +364,7 @@ func handleFeedManagementKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 		m.AddFeedCat = ""
 		m.AddFeedTags = ""
 		m.AddFeedField = "url"
+		m.AddFeedError = ""
 		return m, nil
 	case "e":
 		// Start editing current feed
@@ -334,6 +373,7 @@ func handleFeedManagementKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 			m.EditingFeed = true
 			m.EditField = "url"
 			m.EditValue = feed.URL
+			m.EditError = ""
 			return m, nil
 		}
 		return m, nil
@@ -346,20 +386,69 @@ func handleFeedManagementKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 	case "r":
 		// Reload feeds from config
 		return m, LoadConfig()
+	case "i":
+		// Import feeds from an OPML file
+		m.OPMLAction = "import"
+		m.OPMLPath = defaultOPMLPath
+		return m, nil
+	case "x":
+		// Export feeds to an OPML file
+		m.OPMLAction = "export"
+		m.OPMLPath = defaultOPMLPath
+		return m, nil
 	}
 
 	return m, nil
 }
 
+// defaultOPMLPath is suggested when importing or exporting OPML
+const defaultOPMLPath = "~/bloom.opml"
+
+// handleOPMLPathKeys handles keyboard input when entering an OPML file path
+func handleOPMLPathKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Cancel
+		m.OPMLAction = ""
+		m.OPMLPath = ""
+		return m, nil
+	case "enter":
+		path := strings.TrimSpace(m.OPMLPath)
+		if path == "" {
+			m.Err = fmt.Errorf("path is required")
+			return m, nil
+		}
+
+		action := m.OPMLAction
+		m.OPMLAction = ""
+		m.OPMLPath = ""
+
+		if action == "import" {
+			return m, ImportOPML(m.Config, path)
+		}
+		return m, ExportOPML(m.Config, path)
+	case "ctrl+v":
+		// Paste from clipboard
+		return m, PasteFromClipboard()
+	case "backspace":
+		if len(m.OPMLPath) > 0 {
+			m.OPMLPath = m.OPMLPath[:len(m.OPMLPath)-1]
+		}
+		return m, nil
+	default:
+		if len(msg.String()) == 1 {
+			m.OPMLPath += msg.String()
+		}
+		return m, nil
+	}
+}
+
 // handleAddFeedKeys handles keyboard input when adding a new feed
 func handleAddFeedKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
 		// Cancel adding
-		m.AddingFeed = false
-		m.AddFeedURL = ""
-		m.AddFeedCat = ""
-		m.AddFeedTags = ""
+		resetAddFeedForm(m)
 		return m, nil
 	case "tab":
 		// Move to next field
@@ -372,10 +461,10 @@ func handleAddFeedKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 			m.AddFeedField = "url"
 		}
 		return m, nil
-	case "enter":
+	case "enter", "ctrl+s":
 		// Save new feed
 		if m.AddFeedURL == "" {
-			m.Err = fmt.Errorf("URL is required")
+			m.AddFeedError = "URL is required"
 			return m, nil
 		}
 
@@ -387,20 +476,32 @@ func handleAddFeedKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 		}
 
 		newFeed := storage.FeedConfig{
-			URL:      m.AddFeedURL,
+			URL:      normalizeFeedURL(m.AddFeedURL),
 			Category: m.AddFeedCat,
 			Tags:     tags,
 		}
 
-		m.AddingFeed = false
-		m.AddFeedURL = ""
-		m.AddFeedCat = ""
-		m.AddFeedTags = ""
-		
-		return m, tea.Batch(
-			AddFeedToConfig(m.Config, newFeed),
-			LoadFeed(newFeed.URL),
-		)
+		if msg.String() == "ctrl+s" {
+			// Save the URL as entered, e.g. a feed discovery can't reach
+			resetAddFeedForm(m)
+			return m, tea.Batch(
+				AddFeedToConfig(m.Config, newFeed),
+				LoadFeed(m.Reader, newFeed.URL),
+			)
+		}
+
+		// Sites that need credentials are searched with those of their
+		// configured feeds
+		client, err := m.Config.DiscoveryClient(m.Clients, newFeed.URL)
+		if err != nil {
+			m.AddFeedError = fmt.Sprintf("Invalid HTTP settings: %v", err)
+			return m, nil
+		}
+		resetAddFeedForm(m)
+
+		// Resolve the URL to a feed first; it may be a site's homepage
+		m.Loading = true
+		return m, DiscoverFeeds(m.Reader, client, newFeed)
 	case "ctrl+v":
 		// Paste from clipboard
 		return m, PasteFromClipboard()
@@ -437,7 +538,59 @@ func handleAddFeedKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 	}
 }
 
-// handleEditFeedKeys handles keyboard input when editing a feed
+// handleFeedPickerKeys handles keyboard input when choosing a discovered feed
+func handleFeedPickerKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Cancel adding
+		m.PickingFeed = false
+		m.DiscoveredFeeds = nil
+		m.PickerCursor = 0
+		return m, nil
+	case "j", "down":
+		if m.PickerCursor < len(m.DiscoveredFeeds)-1 {
+			m.PickerCursor++
+		}
+		return m, nil
+	case "k", "up":
+		if m.PickerCursor > 0 {
+			m.PickerCursor--
+		}
+		return m, nil
+	case "enter":
+		if m.PickerCursor >= len(m.DiscoveredFeeds) {
+			return m, nil
+		}
+
+		newFeed := m.PendingFeed
+		newFeed.URL = m.DiscoveredFeeds[m.PickerCursor].URL
+
+		m.PickingFeed = false
+		m.DiscoveredFeeds = nil
+		m.PickerCursor = 0
+
+		return m, tea.Batch(
+			AddFeedToConfig(m.Config, newFeed),
+			LoadFeed(m.Reader, newFeed.URL),
+		)
+	}
+
+	return m, nil
+}
+
+// resetAddFeedForm closes the add feed form and clears it
+func resetAddFeedForm(m *Model) {
+	m.AddingFeed = false
+	m.AddFeedURL = ""
+	m.AddFeedCat = ""
+	m.AddFeedTags = ""
+	m.AddFeedField = "url"
+	m.AddFeedError = ""
+}
+
+// handleEditFeedKeys handles keyboard input when editing a feed. Typed
+// fields take text; choice fields cycle through their values with space or
+// the arrow keys.
 func handleEditFeedKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 	if m.Cursor >= len(m.Config.Feeds) {
 		m.EditingFeed = false
@@ -445,6 +598,12 @@ func handleEditFeedKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 	}
 
 	feed := m.Config.Feeds[m.Cursor]
+	index := components.FindFeedFormField(m.EditField)
+	if index < 0 {
+		m.EditingFeed = false
+		return m, nil
+	}
+	field := components.FeedFormFields[index]
 
 	switch msg.String() {
 	case "esc":
@@ -452,54 +611,54 @@ func handleEditFeedKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 		m.EditingFeed = false
 		m.EditField = ""
 		m.EditValue = ""
+		m.EditError = ""
 		return m, nil
-	case "tab":
-		// Move to next field and save current
-		switch m.EditField {
-		case "url":
-			feed.URL = m.EditValue
-			m.EditField = "category"
-			m.EditValue = feed.Category
-		case "category":
-			feed.Category = m.EditValue
-			m.EditField = "tags"
-			m.EditValue = strings.Join(feed.Tags, ", ")
-		case "tags":
-			// Parse tags
-			tags := []string{}
-			if m.EditValue != "" {
-				for _, tag := range strings.Split(m.EditValue, ",") {
-					tags = append(tags, strings.TrimSpace(tag))
-				}
-			}
-			feed.Tags = tags
-			m.EditField = "url"
-			m.EditValue = feed.URL
+	case "tab", "shift+tab":
+		// Save the current field and move to the next or previous one
+		if err := setFeedField(&feed, m.EditField, m.EditValue); err != nil {
+			m.EditError = err.Error()
+			return m, nil
 		}
+		m.EditError = ""
 		m.Config.Feeds[m.Cursor] = feed
+
+		step := 1
+		if msg.String() == "shift+tab" {
+			step = len(components.FeedFormFields) - 1
+		}
+		next := components.FeedFormFields[(index+step)%len(components.FeedFormFields)]
+		m.EditField = next.Key
+		m.EditValue = components.FeedFieldValue(feed, next.Key)
 		return m, nil
 	case "enter":
 		// Save changes
-		switch m.EditField {
-		case "url":
-			feed.URL = m.EditValue
-		case "category":
-			feed.Category = m.EditValue
-		case "tags":
-			tags := []string{}
-			if m.EditValue != "" {
-				for _, tag := range strings.Split(m.EditValue, ",") {
-					tags = append(tags, strings.TrimSpace(tag))
-				}
-			}
-			feed.Tags = tags
+		if err := setFeedField(&feed, m.EditField, m.EditValue); err != nil {
+			m.EditError = err.Error()
+			return m, nil
 		}
+		m.EditError = ""
 
 		m.EditingFeed = false
 		m.EditField = ""
 		m.EditValue = ""
-		
+
 		return m, UpdateFeedInConfig(m.Config, m.Cursor, feed)
+	}
+
+	if field.Choices != nil {
+		step := 0
+		switch msg.String() {
+		case " ", "right", "l":
+			step = 1
+		case "left", "h":
+			step = len(field.Choices) - 1
+		}
+		current := max(slices.Index(field.Choices, m.EditValue), 0)
+		m.EditValue = field.Choices[(current+step)%len(field.Choices)]
+		return m, nil
+	}
+
+	switch msg.String() {
 	case "ctrl+v":
 		// Paste from clipboard
 		return m, PasteFromClipboard()
@@ -518,6 +677,62 @@ func handleEditFeedKeys(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 	}
 }
 
+// setFeedField sets the field of the feed edit form with the given key
+func setFeedField(feed *storage.FeedConfig, key, value string) error {
+	switch key {
+	case "url":
+		if value != feed.URL {
+			// A new URL gives a disabled feed another chance
+			feed.Disabled = false
+		}
+		feed.URL = value
+	case "title":
+		feed.Title = strings.TrimSpace(value)
+	case "category":
+		feed.Category = value
+	case "tags":
+		tags := []string{}
+		if value != "" {
+			for _, tag := range strings.Split(value, ",") {
+				tags = append(tags, strings.TrimSpace(tag))
+			}
+		}
+		feed.Tags = tags
+	case "paused":
+		feed.Paused = value == "yes"
+	case "refresh", "max_items":
+		n := 0
+		if value = strings.TrimSpace(value); value != "" {
+			var err error
+			n, err = strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("%s must be a whole number of at least 0, got %q", key, value)
+			}
+		}
+		if key == "refresh" {
+			feed.RefreshIntervalMin = n
+		} else {
+			feed.MaxItems = n
+		}
+	case "open":
+		feed.OpenAction = value
+		if value == storage.OpenExtract {
+			feed.OpenAction = ""
+		}
+	case "mark_read":
+		switch value {
+		case "yes", "no":
+			markRead := value == "yes"
+			feed.MarkReadOnView = &markRead
+		default:
+			feed.MarkReadOnView = nil
+		}
+	case "notify":
+		feed.Notify = value == "yes"
+	}
+	return nil
+}
+
 // Content view cursor movement handlers
 func handleContentDown(m *Model) (*Model, tea.Cmd) {
 	if m.CurrentView != "content" {
@@ -3,11 +3,14 @@ package tui
 import (
 	"bloom/internal/feed"
 	"bloom/internal/storage"
+	"context"
+	"time"
 )
 
 type StateLoadMsg struct {
-	State *storage.AppState
-	Err   error
+	State     *storage.AppState
+	Compacted int // Read marks dropped by the retention policy
+	Err       error
 }
 
 type StateSaveMsg struct {
@@ -16,12 +19,19 @@ type StateSaveMsg struct {
 
 // FeedLoadMsg is sent when a feed has been loaded
 type FeedLoadMsg struct {
-	Channel *feed.Channel
-	Err     error
+	URL string // Normalized feed URL, set even when loading failed
+	feed.FetchResult
+	Err error
+}
+
+// CachedFeedsLoadMsg is sent when the offline copies of the feeds have been read
+type CachedFeedsLoadMsg struct {
+	Channels []feed.Channel
 }
 
 // ArticleLoadMsg is sent when an article has been loaded
 type ArticleLoadMsg struct {
+	ItemID  string // ID of the item the article is for
 	Article feed.Article
 	Err     error
 }
@@ -44,9 +54,27 @@ type ConfigLoadMsg struct {
 	Err    error
 }
 
-// FeedsLoadedMsg is sent when all feeds from config have been loaded
-type FeedsLoadedMsg struct {
-	Count int
+// RefreshTickMsg is sent periodically to refresh the feeds that are due
+type RefreshTickMsg struct {
+	Time time.Time
+}
+
+// RefreshStartedMsg is sent when a refresh of all feeds has been started
+type RefreshStartedMsg struct {
+	Total   int
+	Updates <-chan feed.RefreshProgress
+	Cancel  context.CancelFunc
+}
+
+// RefreshProgressMsg is sent each time a feed of a refresh has been fetched
+type RefreshProgressMsg struct {
+	Updates  <-chan feed.RefreshProgress // Identifies the refresh
+	Progress feed.RefreshProgress
+}
+
+// RefreshDoneMsg is sent when every feed of a refresh has been fetched
+type RefreshDoneMsg struct {
+	Updates <-chan feed.RefreshProgress
 }
 
 // FeedAddedMsg is sent when a feed has been added to config
@@ -55,6 +83,13 @@ type FeedAddedMsg struct {
 	Err  error
 }
 
+// FeedsDiscoveredMsg is sent when feed discovery for a new feed has finished
+type FeedsDiscoveredMsg struct {
+	Feed  storage.FeedConfig // The feed being added, with the URL the user entered
+	Feeds []feed.DiscoveredFeed
+	Err   error
+}
+
 // FeedDeletedMsg is sent when a feed has been deleted from config
 type FeedDeletedMsg struct {
 	Index int
@@ -78,3 +113,18 @@ type ClipboardPasteMsg struct {
 	Content string
 	Err     error
 }
+
+// OPMLImportedMsg is sent when feeds have been imported from an OPML file
+type OPMLImportedMsg struct {
+	Path  string
+	Added []storage.FeedConfig // Feeds that weren't configured yet
+	Total int                  // Feeds found in the file
+	Err   error
+}
+
+// OPMLExportedMsg is sent when the feeds have been exported to an OPML file
+type OPMLExportedMsg struct {
+	Path  string
+	Count int
+	Err   error
+}
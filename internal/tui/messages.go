@@ -3,8 +3,12 @@ package tui
 import (
 	"bloom/internal/feed"
 	"bloom/internal/storage"
+	"bloom/internal/tui/components"
 	"bloom/internal/tui/utils"
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
@@ -13,20 +17,209 @@ import (
 // Message handlers
 func handleFeedLoad(m *Model, msg FeedLoadMsg) (*Model, tea.Cmd) {
 	m.Loading = false
+	cmd := applyFetch(m, msg.URL, msg.FetchResult, msg.Err)
+	return m, tea.Batch(cmd, saveStateIfLoaded(m))
+}
 
-	if msg.Err != nil {
-		m.Err = msg.Err
-		return m, nil
+// applyFetch merges the outcome of fetching a feed into the model. Failures
+// are recorded in the feed's health, and the offline copy, if any, stays on
+// screen. Feeds that moved or are gone are updated in the config, which is
+// saved by the returned command, which also sends the desktop notification
+// of feeds that ask for one when new items arrived.
+func applyFetch(m *Model, feedURL string, result feed.FetchResult, err error) tea.Cmd {
+	// Recorded as results arrive, so feeds a cancelled refresh didn't get
+	// to stay due
+	m.LastRefresh[feedURL] = time.Now()
+
+	var cmds []tea.Cmd
+	if err != nil {
+		if cached := findFeed(m, feedURL); cached != nil {
+			cached.Stale = true
+		}
+	} else if result.Channel != nil {
+		newCount := mergeFeed(m, *result.Channel)
+		if feedConfig, ok := m.Config.FindFeed(feedURL); ok && feedConfig.Notify && newCount > 0 {
+			title := feedConfig.DisplayTitle(result.Channel.Title)
+			cmds = append(cmds, Notify(title, fmt.Sprintf("%d new item(s)", newCount)))
+		}
+	}
+
+	outcome := storage.UpdateAfterFetch(m.Config, m.State, feedURL, time.Now(), result, err)
+	if !outcome.ConfigChanged() {
+		return tea.Batch(cmds...)
+	}
+
+	if outcome.MovedTo != "" {
+		moveFeed(m, feedURL, outcome.MovedTo)
+		cmds = append(cmds, MoveCachedFeed(m.Cache, feedURL, outcome.MovedTo))
+	}
+	return tea.Batch(append(cmds, SaveConfig(m.Config))...)
+}
+
+// configWarning summarizes a problem with the config in one line
+func configWarning(err error) string {
+	var configErr *storage.ConfigError
+	if !errors.As(err, &configErr) {
+		return ""
+	}
+	if configErr.Backup != "" {
+		return "Config unreadable, using defaults (copy at " + configErr.Backup + ")"
+	}
+
+	problems := strings.Split(configErr.Err.Error(), "\n")
+	warning := "Config: " + problems[0]
+	if len(problems) > 1 {
+		warning += fmt.Sprintf(" (+%d more)", len(problems)-1)
+	}
+	return warning
+}
+
+// moveFeed points the session state of a feed at its new URL
+func moveFeed(m *Model, oldURL, newURL string) {
+	if channel := findFeed(m, oldURL); channel != nil {
+		if findFeed(m, newURL) != nil {
+			// Already subscribed at the new URL; the config dropped the old entry
+			for i := range m.Feeds {
+				if m.Feeds[i].FeedURL == oldURL {
+					m.Feeds = append(m.Feeds[:i], m.Feeds[i+1:]...)
+					break
+				}
+			}
+		} else {
+			channel.FeedURL = newURL
+		}
 	}
 
-	if msg.Channel != nil {
-		m.Feeds = append(m.Feeds, *msg.Channel)
-		m.Err = nil
+	m.LastRefresh[newURL] = m.LastRefresh[oldURL]
+	delete(m.LastRefresh, oldURL)
+	if n, ok := m.NewItems[oldURL]; ok {
+		m.NewItems[newURL] += n
+		delete(m.NewItems, oldURL)
 	}
 
+	// Per-feed clients are keyed by URL
+	configureClients(m)
+}
+
+// configureClients points the reader and fetcher at clients built from the
+// config. Invalid HTTP settings are reported in the status bar; fetching
+// goes on with the clients that could be built.
+func configureClients(m *Model) {
+	m.HTTPWarning = ""
+	if err := m.Config.ConfigureClients(m.Clients, m.Reader, m.Fetcher); err != nil {
+		m.HTTPWarning = "Invalid HTTP settings: " + strings.ReplaceAll(err.Error(), "\n", "; ")
+	}
+}
+
+// saveStateIfLoaded saves the state, unless it hasn't been loaded from disk
+// yet and saving would overwrite it
+func saveStateIfLoaded(m *Model) tea.Cmd {
+	if !m.StateLoaded {
+		return nil
+	}
+	return SaveState(m.State)
+}
+
+func handleCachedFeedsLoad(m *Model, msg CachedFeedsLoadMsg) (*Model, tea.Cmd) {
+	for _, channel := range msg.Channels {
+		// A fresh copy may have arrived first
+		if findFeed(m, channel.FeedURL) != nil {
+			continue
+		}
+		applyReadState(m, &channel)
+		m.Feeds = append(m.Feeds, channel)
+	}
 	return m, nil
 }
 
+// findFeed returns the loaded feed fetched from feedURL, or nil
+func findFeed(m *Model, feedURL string) *feed.Channel {
+	for i := range m.Feeds {
+		if m.Feeds[i].FeedURL == feedURL {
+			return &m.Feeds[i]
+		}
+	}
+	return nil
+}
+
+// mergeFeed merges a freshly fetched feed into its loaded copy, or adds it
+// if it's new. Items that dropped out of the feed are kept for the session,
+// the article cursor stays on the same item and unread new items are
+// counted in m.NewItems.
+func mergeFeed(m *Model, channel feed.Channel) int {
+	applyReadState(m, &channel)
+
+	var feedConfig storage.FeedConfig
+	if m.Config != nil {
+		feedConfig, _ = m.Config.FindFeed(channel.FeedURL)
+	}
+
+	existing := findFeed(m, channel.FeedURL)
+	if existing == nil {
+		channel.Item = feedConfig.LimitItems(channel.Item)
+		m.Feeds = append(m.Feeds, channel)
+		return 0
+	}
+
+	// Remember the selected item if this feed's articles are on screen
+	var selected string
+	viewing := (m.CurrentView == "articles" || m.CurrentView == "content") &&
+		m.getLoadedFeedForConfigIndex(m.CurrentFeed) == existing
+	if viewing && m.Cursor < len(existing.Item) {
+		selected = existing.Item[m.Cursor].ID()
+	}
+
+	known := make(map[string]bool, len(existing.Item))
+	for _, item := range existing.Item {
+		known[item.ID()] = true
+	}
+
+	fetched := make(map[string]bool, len(channel.Item))
+	newCount := 0
+	for _, item := range channel.Item {
+		key := item.ID()
+		fetched[key] = true
+		if !known[key] && !item.Read {
+			newCount++
+		}
+	}
+	for _, item := range existing.Item {
+		if !fetched[item.ID()] {
+			channel.Item = append(channel.Item, item)
+		}
+	}
+	feed.SortItems(channel.Item)
+	channel.Item = feedConfig.LimitItems(channel.Item)
+
+	*existing = channel
+	if newCount > 0 {
+		m.NewItems[channel.FeedURL] += newCount
+	}
+
+	if selected != "" {
+		for i, item := range channel.Item {
+			if item.ID() == selected {
+				m.Cursor = i
+				break
+			}
+		}
+		if m.Cursor >= len(channel.Item) {
+			m.Cursor = max(len(channel.Item)-1, 0)
+		}
+	}
+	return newCount
+}
+
+// applyReadState sets the read flag of each item from the saved state
+func applyReadState(m *Model, channel *feed.Channel) {
+	if m.State == nil {
+		return
+	}
+	for i := range channel.Item {
+		channel.Item[i].Read = m.State.IsItemRead(channel.Item[i])
+	}
+}
+
 func handleArticleLoad(m *Model, msg ArticleLoadMsg) (*Model, tea.Cmd) {
 	m.Loading = false
 
@@ -38,26 +231,7 @@ func handleArticleLoad(m *Model, msg ArticleLoadMsg) (*Model, tea.Cmd) {
 	m.CurrentArticle = msg.Article
 	m.ArticleContent = msg.Article.Content
 
-	// Mark article as read
-	if m.State != nil {
-		m.State.MarkAsRead(msg.Article.URL)
-		// Update the read status in the feed list
-		if m.Config != nil && m.CurrentFeed < len(m.Config.Feeds) {
-			feedConfig := m.Config.Feeds[m.CurrentFeed]
-			// Find matching loaded feed by FeedURL
-			for i := range m.Feeds {
-				if m.Feeds[i].FeedURL == feedConfig.URL {
-					for j := range m.Feeds[i].Item {
-						if m.Feeds[i].Item[j].Link == msg.Article.URL {
-							m.Feeds[i].Item[j].Read = true
-							break
-						}
-					}
-					break
-				}
-			}
-		}
-	}
+	markViewed(m, msg.ItemID)
 
 	// Render markdown with glamour and parse into lines for scrolling
 	renderedContent, err := renderMarkdownForScrolling(msg.Article.Content, m.Width-8)
@@ -81,24 +255,59 @@ func handleArticleLoad(m *Model, msg ArticleLoadMsg) (*Model, tea.Cmd) {
 	return m, SaveState(m.State)
 }
 
+// markViewed marks the item of the current feed with the given ID as read,
+// unless the feed's settings say viewing an item doesn't
+func markViewed(m *Model, id string) {
+	if m.State == nil || m.Config == nil || m.CurrentFeed >= len(m.Config.Feeds) {
+		return
+	}
+	feedConfig := m.Config.Feeds[m.CurrentFeed]
+	if !m.Config.MarkReadOnViewFor(feedConfig) {
+		return
+	}
+	// Update the read status in the feed list
+	channel := findFeed(m, feedConfig.URL)
+	if channel == nil {
+		return
+	}
+	for i := range channel.Item {
+		if channel.Item[i].ID() == id {
+			m.State.MarkItemRead(channel.Item[i])
+			channel.Item[i].Read = true
+			break
+		}
+	}
+}
+
 func handleStateLoad(m *Model, msg StateLoadMsg) (*Model, tea.Cmd) {
 	if msg.Err != nil {
 		// If state loading fails, just use empty state
 		m.State = &storage.AppState{
-			ReadArticles: make(map[string]bool),
+			ReadArticles: make(map[string]storage.ReadMark),
 		}
 		return m, nil
 	}
 
+	// Keep the health of feeds fetched while the state was loading
+	for url, health := range m.State.FeedHealth {
+		if saved := msg.State.Health(url); saved == nil || saved.LastAttempt.Before(health.LastAttempt) {
+			msg.State.FeedHealth[url] = health
+		}
+	}
+
 	m.State = msg.State
+	m.StateLoaded = true
 
 	// Mark articles as read based on loaded state
 	for i := range m.Feeds {
 		for j := range m.Feeds[i].Item {
-			m.Feeds[i].Item[j].Read = m.State.IsRead(m.Feeds[i].Item[j].Link)
+			m.Feeds[i].Item[j].Read = m.State.IsItemRead(m.Feeds[i].Item[j])
 		}
 	}
 
+	if msg.Compacted > 0 {
+		return m, SaveState(m.State)
+	}
 	return m, nil
 }
 
@@ -110,34 +319,119 @@ func handleStateSave(m *Model, msg StateSaveMsg) (*Model, tea.Cmd) {
 }
 
 func handleConfigLoad(m *Model, msg ConfigLoadMsg) (*Model, tea.Cmd) {
-	if msg.Err != nil {
+	var loadState tea.Cmd
+	if !m.StateLoaded {
+		loadState = LoadState(msg.Config, m.Cache)
+	}
+
+	if msg.Err != nil && msg.Config == nil {
 		m.Err = msg.Err
-		return m, nil
+		return m, loadState
 	}
+	m.ConfigWarning = configWarning(msg.Err)
 
 	m.Config = msg.Config
 
-	// Clear existing feeds to prevent duplicates when reloading config
-	m.Feeds = []feed.Channel{}
+	// Fetch with the configured user agent, proxy, timeouts and retries
+	configureClients(m)
 
-	// Load all feeds from config (URLs are already normalized by LoadConfig)
-	var cmds []tea.Cmd
+	// Drop feeds that are no longer configured; the rest stay on screen
+	// until fresh copies are merged in
+	configured := make(map[string]bool, len(msg.Config.Feeds))
+	for _, feedConfig := range msg.Config.Feeds {
+		configured[normalizeFeedURL(feedConfig.URL)] = true
+	}
+	feeds := []feed.Channel{}
+	for _, channel := range m.Feeds {
+		if configured[channel.FeedURL] {
+			feeds = append(feeds, channel)
+		}
+	}
+	m.Feeds = feeds
+
+	// Show the offline copies first, then refresh every feed
+	var urls []string
 	for _, feedConfig := range msg.Config.Feeds {
-		cmds = append(cmds, LoadFeed(feedConfig.URL))
+		if feedConfig.Enabled() {
+			urls = append(urls, normalizeFeedURL(feedConfig.URL))
+		}
 	}
+	return m, tea.Batch(
+		loadState,
+		LoadCachedFeeds(m.Cache, msg.Config),
+		RefreshFeeds(m.Scheduler, urls),
+	)
+}
 
-	// Batch all feed load commands
-	if len(cmds) > 0 {
-		return m, tea.Batch(cmds...)
+func handleRefreshTick(m *Model, msg RefreshTickMsg) (*Model, tea.Cmd) {
+	// Let a refresh in progress finish; due feeds are picked up next tick
+	if m.Refreshing || m.Config == nil {
+		return m, RefreshTick()
 	}
 
-	return m, nil
+	var due []string
+	for _, feedConfig := range m.Config.Feeds {
+		interval := m.Config.RefreshInterval(feedConfig)
+		if interval == 0 || !feedConfig.Enabled() {
+			continue
+		}
+		url := normalizeFeedURL(feedConfig.URL)
+		next := m.LastRefresh[url].Add(interval)
+		if channel := findFeed(m, url); channel != nil {
+			// The publisher may ask us to poll less often
+			next = channel.NextRefresh(m.LastRefresh[url], interval)
+		}
+		if !msg.Time.Before(next) {
+			due = append(due, url)
+		}
+	}
+
+	if len(due) == 0 {
+		return m, RefreshTick()
+	}
+	return m, tea.Batch(RefreshFeeds(m.Scheduler, due), RefreshTick())
 }
 
-func handleFeedsLoaded(m *Model, msg FeedsLoadedMsg) (*Model, tea.Cmd) {
-	// All feeds have been requested to load
-	// Individual FeedLoadMsg messages will arrive as they complete
-	return m, nil
+func handleRefreshStarted(m *Model, msg RefreshStartedMsg) (*Model, tea.Cmd) {
+	// A newer refresh replaces the one in progress
+	if m.CancelRefresh != nil {
+		m.CancelRefresh()
+	}
+
+	m.Refreshing = true
+	m.RefreshProgress = feed.RefreshProgress{Total: msg.Total}
+	m.RefreshUpdates = msg.Updates
+	m.CancelRefresh = msg.Cancel
+	return m, WaitForRefresh(msg.Updates)
+}
+
+func handleRefreshProgress(m *Model, msg RefreshProgressMsg) (*Model, tea.Cmd) {
+	// Ignore what's left of a cancelled refresh
+	if msg.Updates != m.RefreshUpdates {
+		return m, nil
+	}
+
+	m.RefreshProgress = msg.Progress
+
+	result := msg.Progress.Result
+	cmd := applyFetch(m, result.URL, result.FetchResult, result.Err)
+	return m, tea.Batch(cmd, WaitForRefresh(msg.Updates))
+}
+
+func handleRefreshDone(m *Model, msg RefreshDoneMsg) (*Model, tea.Cmd) {
+	if msg.Updates != m.RefreshUpdates {
+		return m, nil
+	}
+
+	m.Refreshing = false
+	m.RefreshUpdates = nil
+	if m.CancelRefresh != nil {
+		m.CancelRefresh()
+		m.CancelRefresh = nil
+	}
+
+	// Persist the feeds' health
+	return m, saveStateIfLoaded(m)
 }
 
 func handleFeedAdded(m *Model, msg FeedAddedMsg) (*Model, tea.Cmd) {
@@ -151,6 +445,39 @@ func handleFeedAdded(m *Model, msg FeedAddedMsg) (*Model, tea.Cmd) {
 	return m, nil
 }
 
+func handleFeedsDiscovered(m *Model, msg FeedsDiscoveredMsg) (*Model, tea.Cmd) {
+	m.Loading = false
+
+	if msg.Err != nil {
+		// Reopen the form so the URL can be fixed or saved as entered
+		m.AddingFeed = true
+		m.AddFeedURL = msg.Feed.URL
+		m.AddFeedCat = msg.Feed.Category
+		m.AddFeedTags = strings.Join(msg.Feed.Tags, ", ")
+		m.AddFeedField = "url"
+		m.AddFeedError = msg.Err.Error()
+		return m, nil
+	}
+
+	// A single feed is added straight away
+	if len(msg.Feeds) == 1 {
+		newFeed := msg.Feed
+		newFeed.URL = msg.Feeds[0].URL
+		return m, tea.Batch(
+			AddFeedToConfig(m.Config, newFeed),
+			LoadFeed(m.Reader, newFeed.URL),
+		)
+	}
+
+	// Several feeds: let the user pick one
+	m.PickingFeed = true
+	m.DiscoveredFeeds = msg.Feeds
+	m.PendingFeed = msg.Feed
+	m.PickerCursor = 0
+	m.Err = nil
+	return m, nil
+}
+
 func handleFeedDeleted(m *Model, msg FeedDeletedMsg) (*Model, tea.Cmd) {
 	if msg.Err != nil {
 		m.Err = msg.Err
@@ -181,7 +508,7 @@ func handleFeedUpdated(m *Model, msg FeedUpdatedMsg) (*Model, tea.Cmd) {
 	// Feed updated successfully
 	// Reload the feed
 	m.Err = nil
-	return m, LoadFeed(msg.Feed.URL)
+	return m, LoadFeed(m.Reader, msg.Feed.URL)
 }
 
 func handleConfigSaved(m *Model, msg ConfigSavedMsg) (*Model, tea.Cmd) {
@@ -211,9 +538,39 @@ func handleClipboardPaste(m *Model, msg ClipboardPasteMsg) (*Model, tea.Cmd) {
 			m.AddFeedTags += msg.Content
 		}
 	} else if m.EditingFeed {
-		m.EditValue += msg.Content
+		if i := components.FindFeedFormField(m.EditField); i >= 0 && components.FeedFormFields[i].Choices == nil {
+			m.EditValue += msg.Content
+		}
+	} else if m.OPMLAction != "" {
+		m.OPMLPath += msg.Content
+	}
+
+	return m, nil
+}
+
+func handleOPMLImported(m *Model, msg OPMLImportedMsg) (*Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.Err = msg.Err
+		return m, nil
+	}
+
+	m.Notice = fmt.Sprintf("Imported %d of %d feeds", len(msg.Added), msg.Total)
+
+	// Load the feeds that are new
+	var cmds []tea.Cmd
+	for _, feedConfig := range msg.Added {
+		cmds = append(cmds, LoadFeed(m.Reader, feedConfig.URL))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func handleOPMLExported(m *Model, msg OPMLExportedMsg) (*Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.Err = msg.Err
+		return m, nil
 	}
 
+	m.Notice = fmt.Sprintf("Exported %d feeds to %s", msg.Count, msg.Path)
 	return m, nil
 }
 
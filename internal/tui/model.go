@@ -1,16 +1,19 @@
 package tui
 
 import (
+	"context"
 	"bloom/internal/feed"
 	"bloom/internal/storage"
 	"bloom/internal/tui/utils"
+	"time"
 )
 
 // Model represents the application state for the TUI
 type Model struct {
 	// State and Config
-	State  *storage.AppState
-	Config *storage.Config
+	State       *storage.AppState
+	StateLoaded bool // Set once State has been read from disk
+	Config      *storage.Config
 
 	// View state
 	CurrentView string
@@ -31,13 +34,24 @@ type Model struct {
 	ShowCategories  bool
 
 	// Services
-	Reader  *feed.Reader
-	Fetcher *feed.ArticleFetcher
+	Reader    *feed.Reader
+	Fetcher   *feed.ArticleFetcher
+	Clients   *feed.ClientFactory // Builds the HTTP clients of Reader and Fetcher from the config
+	Cache     *storage.FeedCache  // Last fetched copy of each feed, for offline startup
+	Scheduler *feed.Scheduler     // Refreshes all feeds with bounded concurrency
 
 	// UI state
 	Loading bool
 	Err     error
 
+	// Refresh state
+	Refreshing      bool
+	RefreshProgress feed.RefreshProgress
+	RefreshUpdates  <-chan feed.RefreshProgress // Updates of the refresh in progress
+	CancelRefresh   context.CancelFunc
+	LastRefresh     map[string]time.Time // When each feed URL was last refreshed
+	NewItems        map[string]int       // Unseen new items per feed URL, since the feed was last opened
+
 	// Window dimensions
 	Width  int
 	Height int
@@ -49,17 +63,40 @@ type Model struct {
 
 	// Feed management state
 	EditingFeed   bool
-	EditField     string // "url", "category", "tags"
+	EditField     string // Key of a components.FeedFormFields entry
 	EditValue     string
+	EditError     string // Why the edited field was rejected, shown on the form
 	AddingFeed    bool
 	AddFeedURL    string
 	AddFeedCat    string
 	AddFeedTags   string
 	AddFeedField  string // Current field being edited when adding
+	AddFeedError  string // Why the feed couldn't be added, shown on the form
+
+	// Feed discovery state (choosing between feeds found on a page)
+	PickingFeed     bool
+	DiscoveredFeeds []feed.DiscoveredFeed
+	PendingFeed     storage.FeedConfig // Category and tags for the feed being added
+	PickerCursor    int
+
+	// OPML import/export state
+	OPMLAction string // "import" or "export" while prompting for a path
+	OPMLPath   string
+	Notice     string // Result of the last import/export, shown in the status bar
+
+	// ConfigWarning summarizes problems found loading the config, shown in
+	// the status bar while the config is used regardless
+	ConfigWarning string
+	HTTPWarning   string // Why the HTTP settings couldn't be applied
 }
 
 // NewModel creates and initializes a new Model
 func NewModel() Model {
+	// Cache responses so refreshes can use conditional requests
+	cache := storage.NewFeedCache()
+	reader := feed.NewReader()
+	reader.SetCache(cache)
+
 	return Model{
 		State:           storage.NewAppState(),
 		Config:          storage.DefaultConfig(),
@@ -73,8 +110,13 @@ func NewModel() Model {
 		Categories:      map[string]int{},
 		CurrentCategory: 0,
 		ShowCategories:  false,
-		Reader:          feed.NewReader(),
+		Reader:          reader,
 		Fetcher:         feed.NewArticleFetcher(),
+		Clients:         feed.NewClientFactory(),
+		Cache:           cache,
+		Scheduler:       feed.NewScheduler(reader),
+		LastRefresh:     map[string]time.Time{},
+		NewItems:        map[string]int{},
 		Loading:         false,
 		Err:             nil,
 		Width:           80,
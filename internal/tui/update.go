@@ -44,18 +44,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		newModel, cmd = handleStateSave(&m, msg)
 		return *newModel, cmd
 
+	case CachedFeedsLoadMsg:
+		newModel, cmd = handleCachedFeedsLoad(&m, msg)
+		return *newModel, cmd
+
 	case ConfigLoadMsg:
 		newModel, cmd = handleConfigLoad(&m, msg)
 		return *newModel, cmd
 
-	case FeedsLoadedMsg:
-		newModel, cmd = handleFeedsLoaded(&m, msg)
+	case RefreshTickMsg:
+		newModel, cmd = handleRefreshTick(&m, msg)
+		return *newModel, cmd
+
+	case RefreshStartedMsg:
+		newModel, cmd = handleRefreshStarted(&m, msg)
+		return *newModel, cmd
+
+	case RefreshProgressMsg:
+		newModel, cmd = handleRefreshProgress(&m, msg)
+		return *newModel, cmd
+
+	case RefreshDoneMsg:
+		newModel, cmd = handleRefreshDone(&m, msg)
 		return *newModel, cmd
 
 	case FeedAddedMsg:
 		newModel, cmd = handleFeedAdded(&m, msg)
 		return *newModel, cmd
 
+	case FeedsDiscoveredMsg:
+		newModel, cmd = handleFeedsDiscovered(&m, msg)
+		return *newModel, cmd
+
 	case FeedDeletedMsg:
 		newModel, cmd = handleFeedDeleted(&m, msg)
 		return *newModel, cmd
@@ -72,6 +92,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		newModel, cmd = handleClipboardPaste(&m, msg)
 		return *newModel, cmd
 
+	case OPMLImportedMsg:
+		newModel, cmd = handleOPMLImported(&m, msg)
+		return *newModel, cmd
+
+	case OPMLExportedMsg:
+		newModel, cmd = handleOPMLExported(&m, msg)
+		return *newModel, cmd
+
 	case tea.WindowSizeMsg:
 		m.Width = msg.Width
 		m.Height = msg.Height
@@ -1,9 +1,11 @@
 package utils
 
 import (
+	"fmt"
 	"html"
 	"regexp"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/mattn/go-runewidth"
@@ -97,3 +99,29 @@ func InsertCursorAtPosition(line string, cursorPos int) string {
 	return result.String()
 }
 
+// RelativeTime formats t relative to now ("5m ago", "3h ago", "2d ago"),
+// falling back to a calendar date for old or future timestamps
+func RelativeTime(t time.Time, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	age := now.Sub(t)
+	switch {
+	case age < -time.Minute:
+		// Publisher clock skew or scheduled posts
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	case age < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+
+	if t.Year() == now.Year() {
+		return t.Local().Format("Jan 2")
+	}
+	return t.Local().Format("Jan 2, 2006")
+}
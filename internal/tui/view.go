@@ -2,6 +2,8 @@ package tui
 
 import (
 	"bloom/internal/feed"
+	"fmt"
+	"strings"
 	"bloom/internal/tui/components"
 	"bloom/internal/tui/styles"
 
@@ -25,6 +27,33 @@ func (m Model) getLoadedFeedForConfigIndex(configIndex int) *feed.Channel {
 	return nil
 }
 
+// refreshStatus describes problems with the config, the refresh in progress
+// or the failures of the last one, and the number of new items
+func (m Model) refreshStatus() string {
+	var parts []string
+	if m.ConfigWarning != "" {
+		parts = append(parts, m.ConfigWarning)
+	}
+	if m.HTTPWarning != "" {
+		parts = append(parts, m.HTTPWarning)
+	}
+	if m.Refreshing {
+		parts = append(parts, "Refreshing "+m.RefreshProgress.String())
+	} else if m.RefreshProgress.Failed > 0 {
+		parts = append(parts, m.RefreshProgress.String())
+	}
+
+	newCount := 0
+	for _, n := range m.NewItems {
+		newCount += n
+	}
+	if newCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d new", newCount))
+	}
+
+	return strings.Join(parts, " · ")
+}
+
 // View is the main view dispatcher (bubbletea interface)
 func (m Model) View() string {
 	width := m.Width
@@ -73,14 +102,24 @@ func (m Model) View() string {
 			m.Config,
 			width,
 			m.Height,
-		) + "\n" + components.RenderLandingStatusBar(width)
+		) + "\n" + components.RenderLandingStatusBar(m.refreshStatus(), width)
 	case "feed":
 		feedCount := len(m.Config.Feeds)
 		if m.Config == nil {
 			feedCount = 0
 		}
-		content = components.RenderFeedList(m.Config.Feeds, m.Feeds, m.CurrentFeed, width)
-		status = components.RenderFeedStatusBar(feedCount, width)
+		content = components.RenderFeedList(m.Config.Feeds, m.Feeds, m.State.FeedHealth, m.NewItems, m.CurrentFeed, width)
+		status = components.RenderFeedStatusBar(feedCount, m.refreshStatus(), width)
+		return lipgloss.JoinVertical(lipgloss.Left, content, status)
+	case "health":
+		failing := 0
+		for _, feedConfig := range m.Config.Feeds {
+			if health := m.State.Health(feedConfig.URL); health != nil && health.Failing() {
+				failing++
+			}
+		}
+		content = components.RenderFeedHealth(m.Config.Feeds, m.State.FeedHealth, width)
+		status = components.RenderFeedHealthStatusBar(failing, width)
 		return lipgloss.JoinVertical(lipgloss.Left, content, status)
 	case "articles":
 		loadedFeed := m.getLoadedFeedForConfigIndex(m.CurrentFeed)
@@ -108,15 +147,22 @@ func (m Model) View() string {
 		)
 	case "manage":
 		// Feed management view
-		if m.AddingFeed {
+		if m.PickingFeed {
+			content = components.RenderFeedPicker(m.DiscoveredFeeds, m.PickerCursor, width)
+			status = styles.RenderStatusBar("Add Feed", fmt.Sprintf("%d feeds found", len(m.DiscoveredFeeds)), "↑↓: Navigate | Enter: Add | Esc: Cancel", width)
+		} else if m.AddingFeed {
 			content = components.RenderAddFeedForm(
 				m.AddFeedURL,
 				m.AddFeedCat,
 				m.AddFeedTags,
 				m.AddFeedField,
+				m.AddFeedError,
 				width,
 			)
-			status = styles.RenderStatusBar("Add Feed", "", "Tab: Next | Enter: Save | Esc: Cancel", width)
+			status = styles.RenderStatusBar("Add Feed", "", "Tab: Next | Enter: Save | Ctrl+S: Save as entered | Esc: Cancel", width)
+		} else if m.OPMLAction != "" {
+			content = components.RenderOPMLPrompt(m.OPMLAction, m.OPMLPath, width)
+			status = styles.RenderStatusBar("Feed Manager", "", "Enter: Confirm | Esc: Cancel", width)
 		} else {
 			content = components.RenderFeedManager(
 				m.Config.Feeds,
@@ -124,9 +170,10 @@ func (m Model) View() string {
 				m.EditingFeed,
 				m.EditField,
 				m.EditValue,
+				m.EditError,
 				width,
 			)
-			status = components.RenderFeedManagerStatusBar(len(m.Config.Feeds), width)
+			status = components.RenderFeedManagerStatusBar(len(m.Config.Feeds), m.Notice, width)
 		}
 		return lipgloss.JoinVertical(lipgloss.Left, content, status)
 	default:
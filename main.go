@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bloom/internal/cli"
 	"bloom/internal/tui"
 	"fmt"
 	"os"
@@ -9,6 +10,17 @@ import (
 )
 
 func main() {
+	args, err := cli.ParseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bloom: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Subcommands run without the terminal UI
+	if len(args) > 0 {
+		os.Exit(cli.Run(args))
+	}
+
 	p := tea.NewProgram(
 		tui.NewModel(),
 		tea.WithAltScreen(),       // Use full terminal screen